@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/daozhonglee/go-util/errorutil"
+)
+
+// ErrInvalidParam 请求参数绑定或校验失败，错误链中的 *errorutil.MultiError 记录了每个字段的错误
+var ErrInvalidParam = errorutil.New(http.StatusBadRequest, "参数错误")
+
+// ErrBodyTooLarge 请求体超过限制，请求体通过 http.MaxBytesReader 限制大小时 Bind 返回该错误
+var ErrBodyTooLarge = errorutil.New(http.StatusRequestEntityTooLarge, "请求体过大")
+
+// MaxBindMemory 解析 multipart 表单时使用的最大内存
+var MaxBindMemory int64 = 32 << 20
+
+// Bind 将请求绑定到结构体指针 v 并校验
+//   - JSON 请求体按 json 标签绑定
+//   - 表单按 form 标签绑定，查询参数按 query 标签绑定，路径参数按 path 标签绑定（http.Request.PathValue）
+//   - 字段有多个标签时按 path > query > form 的顺序取第一个有值的参数，与 schema 生成文档的顺序一致
+//   - 绑定后按 validate 标签校验，规则见 Validate
+//
+// 失败时返回 ErrInvalidParam，可通过 FieldErrors 获取每个字段的错误
+func Bind(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("api: Bind requires a pointer to struct, got %T", v)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		if r.Body != nil && r.Body != http.NoBody {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return bodyError(err)
+			}
+			if len(body) > 0 {
+				if err := sonic.Unmarshal(body, v); err != nil {
+					return ErrInvalidParam.WithMessage("请求体格式错误").WithCause(err)
+				}
+			}
+		}
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(MaxBindMemory); err != nil {
+			return bodyError(err)
+		}
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return bodyError(err)
+		}
+	}
+
+	var errs errorutil.MultiError
+	bindValues(rv.Elem(), &errs, func(field reflect.StructField) ([]string, string, bool) {
+		if name := tagName(field, "path"); name != "" {
+			if value := r.PathValue(name); value != "" {
+				return []string{value}, name, true
+			}
+		}
+		if name := tagName(field, "query"); name != "" {
+			if values, ok := r.URL.Query()[name]; ok {
+				return values, name, true
+			}
+		}
+		if name := tagName(field, "form"); name != "" && r.PostForm != nil {
+			if values, ok := r.PostForm[name]; ok {
+				return values, name, true
+			}
+		}
+		return nil, "", false
+	})
+	if errs.Len() > 0 {
+		return ErrInvalidParam.WithCause(&errs)
+	}
+
+	return Validate(v)
+}
+
+func bodyError(err error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return ErrBodyTooLarge.WithCause(err)
+	}
+	return ErrInvalidParam.WithMessage("请求体格式错误").WithCause(err)
+}
+
+func tagName(field reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// bindValues 按 lookup 返回的字符串值填充结构体字段，匿名嵌入的结构体会展开
+func bindValues(rv reflect.Value, errs *errorutil.MultiError, lookup func(reflect.StructField) ([]string, string, bool)) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			bindValues(fv, errs, lookup)
+			continue
+		}
+		values, name, ok := lookup(field)
+		if !ok {
+			continue
+		}
+		if err := setValues(fv, values); err != nil {
+			errs.Add(name, err)
+		}
+	}
+}
+
+func setValues(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return setValue(fv, values[0])
+}
+
+func setValue(fv reflect.Value, value string) error {
+	if fv.Kind() == reflect.Pointer {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("必须是布尔值")
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("必须是整数")
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return errors.New("必须是非负整数")
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return errors.New("必须是数字")
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("不支持的参数类型 %s", fv.Type())
+	}
+	return nil
+}
+
+// ValidationResponse 将 Bind、Validate 返回的错误转换为带字段错误列表的响应
+func ValidationResponse(err error) *DataResponse {
+	code, message := errorCodeMessage(err, "")
+	response, _ := NewDataResponse(message, code, FieldErrors(err))
+	return response
+}
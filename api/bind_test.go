@@ -0,0 +1,158 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type createOrderRequest struct {
+	UserID   int64    `path:"user_id" validate:"required,min=1"`
+	Source   string   `query:"source" validate:"enum=app|web"`
+	Tags     []string `query:"tag" validate:"max=3"`
+	Name     string   `json:"name" validate:"required,max=10"`
+	IDCard   string   `json:"id_card" validate:"chinese_id"`
+	Phone    string   `json:"phone" validate:"regex=^1[0-9]{10}$"`
+	Quantity int      `json:"quantity" validate:"min=1,max=99"`
+}
+
+func newBindRequest(method, target, contentType, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func TestBind(t *testing.T) {
+	r := newBindRequest("POST", "/users/42/orders?source=app&tag=a&tag=b", "application/json",
+		`{"name":"alice","id_card":"11010119900307001X","phone":"13800138000","quantity":2}`)
+	r.SetPathValue("user_id", "42")
+
+	var req createOrderRequest
+	if err := Bind(r, &req); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if req.UserID != 42 || req.Source != "app" || len(req.Tags) != 2 || req.Name != "alice" || req.Quantity != 2 {
+		t.Errorf("Unexpected bound request: %+v", req)
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	type loginRequest struct {
+		Username string `form:"username" validate:"required"`
+		Remember *bool  `form:"remember"`
+	}
+
+	r := newBindRequest("POST", "/login", "application/x-www-form-urlencoded", "username=alice&remember=true")
+	var req loginRequest
+	if err := Bind(r, &req); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if req.Username != "alice" || req.Remember == nil || !*req.Remember {
+		t.Errorf("Unexpected bound request: %+v", req)
+	}
+}
+
+func TestBindTagPriority(t *testing.T) {
+	type searchRequest struct {
+		Keyword string `query:"q" form:"keyword" json:"keyword" validate:"max=3"`
+	}
+
+	// query 优先于 form，字段名与 Bind 取值的标签一致
+	r := newBindRequest("POST", "/search?q=golang", "application/x-www-form-urlencoded", "keyword=go")
+	var req searchRequest
+	err := Bind(r, &req)
+	if req.Keyword != "golang" {
+		t.Errorf("Expected query value, got %+v", req)
+	}
+	if fields := FieldErrors(err); len(fields) != 1 || fields[0].Field != "q" {
+		t.Errorf("Expected field error for q, got %+v", fields)
+	}
+}
+
+func TestBindValidationErrors(t *testing.T) {
+	r := newBindRequest("POST", "/users/x/orders?source=mini&tag=a&tag=b&tag=c&tag=d", "application/json",
+		`{"id_card":"123","phone":"12345","quantity":100}`)
+	r.SetPathValue("user_id", "x")
+
+	var req createOrderRequest
+	err := Bind(r, &req)
+	if err == nil {
+		t.Fatal("Expected bind error")
+	}
+
+	// 类型转换失败时直接返回，不再校验
+	fields := FieldErrors(err)
+	if len(fields) != 1 || fields[0].Field != "user_id" || fields[0].Message != "必须是整数" {
+		t.Errorf("Unexpected field errors: %+v", fields)
+	}
+
+	r = newBindRequest("POST", "/users/42/orders?source=mini&tag=a&tag=b&tag=c&tag=d", "application/json",
+		`{"id_card":"123","phone":"12345","quantity":100}`)
+	r.SetPathValue("user_id", "42")
+	err = Bind(r, &req)
+
+	response := ValidationResponse(err)
+	if response.Code != ErrInvalidParam.Code || response.Message != "参数错误" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	expected := map[string]string{
+		"source":   "必须是 app, web 之一",
+		"tag":      "长度不能大于 3",
+		"name":     "不能为空",
+		"id_card":  "身份证号格式不正确",
+		"phone":    "格式不正确",
+		"quantity": "不能大于 99",
+	}
+	fields = response.Data.([]FieldError)
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d field errors, got %+v", len(expected), fields)
+	}
+	for _, field := range fields {
+		if expected[field.Field] != field.Message {
+			t.Errorf("Unexpected error for %s: %s", field.Field, field.Message)
+		}
+	}
+}
+
+func TestBindInvalidJSON(t *testing.T) {
+	var req createOrderRequest
+	err := Bind(newBindRequest("POST", "/orders", "application/json", `{"name":`), &req)
+	fields := FieldErrors(err)
+	if len(fields) != 1 || fields[0].Message != "请求体格式错误" {
+		t.Errorf("Unexpected field errors: %+v", fields)
+	}
+}
+
+func TestValidateNested(t *testing.T) {
+	type item struct {
+		SKU string `json:"sku" validate:"required"`
+	}
+	type order struct {
+		Items []item `json:"items" validate:"required,min=1"`
+	}
+
+	err := Validate(order{Items: []item{{SKU: "a"}, {}}})
+	fields := FieldErrors(err)
+	if len(fields) != 1 || fields[0].Field != "items[1].sku" {
+		t.Errorf("Unexpected field errors: %+v", fields)
+	}
+	if err := Validate(&order{Items: []item{{SKU: "a"}}}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestWriteValidationError(t *testing.T) {
+	type request struct {
+		Name string `json:"name" validate:"required"`
+	}
+	w := httptest.NewRecorder()
+	WriteError(w, httptest.NewRequest("POST", "/", nil), Validate(request{}))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	expected := `{"message":"参数错误","code":400,"data":[{"field":"name","message":"不能为空"}]}`
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
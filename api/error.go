@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/daozhonglee/go-util/errorutil"
+)
+
+// DefaultErrorMessage 非业务错误返回给用户的提示信息，避免内部错误信息泄露
+var DefaultErrorMessage = "服务内部错误"
+
+// FromError 将错误转换为响应，err 为nil时返回成功响应
+// 业务错误(*errorutil.Error)使用其错误码和提示信息，其他错误使用 errorutil.CodeUnknown 和 DefaultErrorMessage
+func FromError(err error) *Response {
+	return FromErrorLocale(err, "")
+}
+
+// FromErrorLocale 将错误转换为响应，已注册的错误码按 locale 返回对应语言的提示信息
+// locale 一般由 LocaleFromRequest 获取
+func FromErrorLocale(err error, locale string) *Response {
+	code, message := errorCodeMessage(err, locale)
+	return &Response{
+		Message: message,
+		Code:    code,
+	}
+}
+
+// FromErrorWithData 将错误转换为带数据的响应
+func FromErrorWithData(err error, data interface{}) *DataResponse {
+	return FromErrorWithDataLocale(err, data, "")
+}
+
+// FromErrorWithDataLocale 将错误转换为带数据的响应，提示信息使用 locale 对应的语言
+func FromErrorWithDataLocale(err error, data interface{}, locale string) *DataResponse {
+	code, message := errorCodeMessage(err, locale)
+	return &DataResponse{
+		Message: message,
+		Code:    code,
+		Data:    data,
+	}
+}
+
+func errorCodeMessage(err error, locale string) (int, string) {
+	if err == nil {
+		return 0, "success"
+	}
+	if e, ok := errorutil.As(err); ok {
+		if locale != "" {
+			return e.Code, e.Localize(locale)
+		}
+		return e.Code, e.Message
+	}
+	return errorutil.CodeUnknown, DefaultErrorMessage
+}
+
+// FieldError 单个字段/条目的错误，批量操作和参数校验使用统一的结构返回
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldErrors 将错误转换为字段错误列表
+// *errorutil.MultiError 的每个条目对应一个字段错误，其他错误转换为 Field 为空的单个字段错误
+func FieldErrors(err error) []FieldError {
+	if err == nil {
+		return []FieldError{}
+	}
+
+	var multi *errorutil.MultiError
+	if !errors.As(err, &multi) {
+		return []FieldError{newFieldError("", err)}
+	}
+	result := make([]FieldError, 0, multi.Len())
+	for _, item := range multi.Items {
+		result = append(result, newFieldError(item.Key, item.Err))
+	}
+	return result
+}
+
+func newFieldError(field string, err error) FieldError {
+	if e, ok := errorutil.As(err); ok {
+		return FieldError{Field: field, Code: e.Code, Message: e.Message}
+	}
+	return FieldError{Field: field, Message: err.Error()}
+}
+
+// FromMultiError 将批量操作的错误转换为带字段错误列表的响应，err 为nil时返回成功响应
+func FromMultiError(err error, code int, message string) *DataResponse {
+	if err == nil {
+		return FromErrorWithData(nil, []FieldError{})
+	}
+	return &DataResponse{
+		Message: message,
+		Code:    code,
+		Data:    FieldErrors(err),
+	}
+}
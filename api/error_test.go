@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/daozhonglee/go-util/errorutil"
+)
+
+func TestErrorKeepsCode(t *testing.T) {
+	_, err := Error("操作失败", 1001)
+	if errorutil.CodeOf(err) != 1001 {
+		t.Errorf("Expected code 1001 in error, got %d", errorutil.CodeOf(err))
+	}
+}
+
+func TestFromError(t *testing.T) {
+	response := FromError(nil)
+	if response.Code != 0 {
+		t.Errorf("Expected code 0 for nil error, got %d", response.Code)
+	}
+
+	response = FromError(errorutil.Wrap(errors.New("db down"), 1001, "操作失败"))
+	if response.Code != 1001 || response.Message != "操作失败" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	// 非业务错误不暴露内部信息
+	response = FromError(errors.New("db down"))
+	if response.Code != errorutil.CodeUnknown || response.Message != DefaultErrorMessage {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestFromErrorWithData(t *testing.T) {
+	data := map[string]string{"field": "name"}
+	response := FromErrorWithData(errorutil.New(1001, "参数错误"), data)
+	if response.Code != 1001 || response.Data == nil {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
+
+func TestFromMultiError(t *testing.T) {
+	var m errorutil.MultiError
+	m.AddIndex(0, errors.New("content is empty"))
+	m.Add("id_card", errorutil.New(1002, "身份证号格式错误"))
+
+	response := FromMultiError(m.ErrorOrNil(), 1001, "部分任务推送失败")
+	if response.Code != 1001 || response.Message != "部分任务推送失败" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	fields, ok := response.Data.([]FieldError)
+	if !ok || len(fields) != 2 {
+		t.Fatalf("Expected 2 field errors, got %v", response.Data)
+	}
+	if fields[0] != (FieldError{Field: "0", Message: "content is empty"}) {
+		t.Errorf("Unexpected field error: %+v", fields[0])
+	}
+	if fields[1] != (FieldError{Field: "id_card", Code: 1002, Message: "身份证号格式错误"}) {
+		t.Errorf("Unexpected field error: %+v", fields[1])
+	}
+
+	if response := FromMultiError(nil, 1001, "部分任务推送失败"); response.Code != 0 {
+		t.Errorf("Expected success response for nil error, got %+v", response)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/json"
+)
+
+// TypedResponse 数据类型确定的响应结构，JSON 字段与 DataResponse 一致
+type TypedResponse[T any] struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    T      `json:"data"`
+}
+
+// TypedPageResponse 数据类型确定的分页响应结构，JSON 字段与 PageResponse 一致
+type TypedPageResponse[T any] struct {
+	Message string     `json:"message"`
+	Code    int        `json:"code"`
+	Data    T          `json:"data"`
+	Page    Pagination `json:"pagination"`
+}
+
+// NewTypedResponse 创建数据类型确定的响应
+func NewTypedResponse[T any](message string, code int, data T) (*TypedResponse[T], error) {
+	response := &TypedResponse[T]{
+		Message: message,
+		Code:    code,
+		Data:    data,
+	}
+	return response, response.Err()
+}
+
+// NewTypedPageResponse 创建数据类型确定的分页响应
+func NewTypedPageResponse[T any](message string, code int, data T, page Pagination) (*TypedPageResponse[T], error) {
+	response := &TypedPageResponse[T]{
+		Message: message,
+		Code:    code,
+		Data:    data,
+		Page:    page,
+	}
+	return response, response.Err()
+}
+
+// SuccessWithTypedData 创建数据类型确定的成功响应
+func SuccessWithTypedData[T any](message string, data T) *TypedResponse[T] {
+	response, _ := NewTypedResponse(message, 0, data)
+	return response
+}
+
+// SuccessWithTypedPage 创建数据类型确定的分页成功响应
+func SuccessWithTypedPage[T any](message string, data T, page Pagination) *TypedPageResponse[T] {
+	response, _ := NewTypedPageResponse(message, 0, data, page)
+	return response
+}
+
+// Err code 不为0时返回对应的业务错误
+func (r *TypedResponse[T]) Err() error {
+	return codeError(r.Code, r.Message)
+}
+
+// Err code 不为0时返回对应的业务错误
+func (r *TypedPageResponse[T]) Err() error {
+	return codeError(r.Code, r.Message)
+}
+
+// Err code 不为0时返回对应的业务错误
+func (r *Response) Err() error {
+	return codeError(r.Code, r.Message)
+}
+
+func codeError(code int, message string) error {
+	if code == 0 {
+		return nil
+	}
+	return errorutil.New(code, message)
+}
+
+// Decode 从响应体解析数据，code 不为0时返回 *errorutil.Error，此时 data 仍为响应中的数据（如字段错误列表）
+func Decode[T any](body []byte) (T, error) {
+	var response TypedResponse[T]
+	if err := json.Unmarshal(body, &response); err != nil {
+		var zero T
+		return zero, fmt.Errorf("api: decode response: %w", err)
+	}
+	return response.Data, response.Err()
+}
+
+// DecodePage 从分页响应体解析数据和分页信息，code 不为0时返回 *errorutil.Error
+func DecodePage[T any](body []byte) (T, Pagination, error) {
+	var response TypedPageResponse[T]
+	if err := json.Unmarshal(body, &response); err != nil {
+		var zero T
+		return zero, Pagination{}, fmt.Errorf("api: decode page response: %w", err)
+	}
+	return response.Data, response.Page, response.Err()
+}
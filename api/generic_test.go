@@ -0,0 +1,61 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/json"
+)
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestTypedResponseJSON(t *testing.T) {
+	response := SuccessWithTypedData("成功", user{ID: 1, Name: "alice"})
+	body := json.MarshalFailSafe(response)
+	expected := `{"message":"成功","code":0,"data":{"id":1,"name":"alice"}}`
+	if body != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+
+	// 与 DataResponse 的 JSON 字段保持一致
+	legacy, _ := SuccessWithData("成功", user{ID: 1, Name: "alice"})
+	if json.MarshalFailSafe(legacy) != body {
+		t.Errorf("Expected same JSON as DataResponse, got %s", json.MarshalFailSafe(legacy))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	u, err := Decode[user]([]byte(`{"message":"成功","code":0,"data":{"id":1,"name":"alice"}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if u.ID != 1 || u.Name != "alice" {
+		t.Errorf("Unexpected data: %+v", u)
+	}
+
+	_, err = Decode[user]([]byte(`{"message":"用户不存在","code":1404,"data":null}`))
+	if errorutil.CodeOf(err) != 1404 || err.Error() != "用户不存在" {
+		t.Errorf("Expected business error 1404, got %v", err)
+	}
+
+	if _, err = Decode[user]([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid body")
+	}
+}
+
+func TestDecodePage(t *testing.T) {
+	body := `{"message":"成功","code":0,"data":[{"id":1,"name":"alice"},{"id":2,"name":"bob"}],"pagination":{"offset":0,"limit":10,"total":2}}`
+	users, page, err := DecodePage[[]user]([]byte(body))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(users) != 2 || users[1].Name != "bob" {
+		t.Errorf("Unexpected data: %+v", users)
+	}
+	if page.Total != 2 || page.Limit != 10 {
+		t.Errorf("Unexpected pagination: %+v", page)
+	}
+}
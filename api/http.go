@@ -0,0 +1,226 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/log"
+)
+
+// 响应头
+var (
+	TraceIDHeader   = "X-Trace-Id"
+	RequestIDHeader = "X-Request-Id"
+)
+
+// 内置支持的响应编码
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeText = "text/plain" // 紧凑的单行文本格式：code=0 message=success data={...}
+)
+
+// Envelope 所有响应结构都实现了该接口，用于推导HTTP状态码
+type Envelope interface {
+	GetCode() int
+	GetMessage() string
+}
+
+func (r *Response) GetCode() int {
+	return r.Code
+}
+
+func (r *Response) GetMessage() string {
+	return r.Message
+}
+
+func (r *DataResponse) GetCode() int {
+	return r.Code
+}
+
+func (r *DataResponse) GetMessage() string {
+	return r.Message
+}
+
+func (r *DataResponse) GetData() interface{} {
+	return r.Data
+}
+
+func (r *PageResponse) GetCode() int {
+	return r.Code
+}
+
+func (r *PageResponse) GetMessage() string {
+	return r.Message
+}
+
+func (r *PageResponse) GetData() interface{} {
+	return r.Data
+}
+
+func (r *TypedResponse[T]) GetCode() int {
+	return r.Code
+}
+
+func (r *TypedResponse[T]) GetMessage() string {
+	return r.Message
+}
+
+func (r *TypedResponse[T]) GetData() interface{} {
+	return r.Data
+}
+
+func (r *TypedPageResponse[T]) GetCode() int {
+	return r.Code
+}
+
+func (r *TypedPageResponse[T]) GetMessage() string {
+	return r.Message
+}
+
+func (r *TypedPageResponse[T]) GetData() interface{} {
+	return r.Data
+}
+
+// Encoder 将响应编码后写入 w
+type Encoder func(w io.Writer, v Envelope) error
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		MediaTypeJSON: encodeJSON,
+		MediaTypeText: encodeText,
+
+		MediaTypeProblemJSON: encodeJSON, // 错误响应在 WriteStatus 中转换为 Problem
+	}
+)
+
+// RegisterEncoder 注册响应编码，Write 根据 Accept 请求头选择，可用于扩展 msgpack 等格式
+func RegisterEncoder(mediaType string, enc Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[mediaType] = enc
+}
+
+func encodeJSON(w io.Writer, v Envelope) error {
+	body, err := sonic.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+func encodeText(w io.Writer, v Envelope) error {
+	if _, err := fmt.Fprintf(w, "code=%d message=%s", v.GetCode(), v.GetMessage()); err != nil {
+		return err
+	}
+	if d, ok := v.(interface{ GetData() interface{} }); ok && d.GetData() != nil {
+		data, err := sonic.Marshal(d.GetData())
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, " data=%s", data); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// negotiate 按 Accept 请求头选择编码，没有匹配时使用JSON
+func negotiate(r *http.Request) (string, Encoder) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	if r != nil {
+		for _, accept := range parseQualityList(r.Header.Get("Accept")) {
+			mediaType, _, err := mime.ParseMediaType(accept)
+			if err != nil {
+				continue
+			}
+			if enc, ok := encoders[mediaType]; ok {
+				return mediaType, enc
+			}
+			if mediaType == "*/*" || mediaType == "application/*" {
+				break
+			}
+			if prefix, ok := strings.CutSuffix(mediaType, "/*"); ok {
+				for mt, enc := range encoders {
+					if strings.HasPrefix(mt, prefix+"/") {
+						return mt, enc
+					}
+				}
+			}
+		}
+	}
+	return MediaTypeJSON, encoders[MediaTypeJSON]
+}
+
+// StatusOf 返回业务错误码对应的HTTP状态码，已注册的错误码使用注册时的状态码
+func StatusOf(code int) int {
+	if e, ok := errorutil.Lookup(code); ok {
+		return e.HTTPStatus()
+	}
+	return errorutil.StatusMapper(code)
+}
+
+// Write 将响应写入 w，HTTP状态码由业务错误码推导
+func Write(w http.ResponseWriter, r *http.Request, v Envelope) error {
+	return WriteStatus(w, r, StatusOf(v.GetCode()), v)
+}
+
+// WriteStatus 使用指定的HTTP状态码写入响应
+// 根据 Accept 请求头选择编码，并写入 TraceID、RequestID 响应头
+func WriteStatus(w http.ResponseWriter, r *http.Request, status int, v Envelope) error {
+	mediaType, enc := negotiate(r)
+	if mediaType == MediaTypeProblemJSON {
+		if status >= http.StatusBadRequest {
+			return writeProblem(w, r, NewProblem(v, status))
+		}
+		mediaType, enc = MediaTypeJSON, encodeJSON
+	}
+
+	writeHeader(w, r, mediaType)
+	w.WriteHeader(status)
+	return enc(w, v)
+}
+
+func writeHeader(w http.ResponseWriter, r *http.Request, mediaType string) {
+	header := w.Header()
+	header.Set("Content-Type", mediaType+"; charset=utf-8")
+	header.Set("X-Content-Type-Options", "nosniff")
+	if r != nil {
+		traceID := ""
+		if v := r.Context().Value(log.TRACEID); v != nil {
+			traceID = fmt.Sprint(v)
+			header.Set(TraceIDHeader, traceID)
+		}
+		if requestID := r.Header.Get(RequestIDHeader); requestID != "" {
+			header.Set(RequestIDHeader, requestID)
+		} else if traceID != "" {
+			header.Set(RequestIDHeader, traceID)
+		}
+	}
+}
+
+// WriteError 将错误写入 w，提示信息使用请求对应的语言，HTTP状态码由错误推导
+// 错误链中包含 *errorutil.MultiError（如 Bind、Validate 的错误）时，data 为字段错误列表
+func WriteError(w http.ResponseWriter, r *http.Request, err error) error {
+	var multi *errorutil.MultiError
+	if errors.As(err, &multi) {
+		return WriteStatus(w, r, errorutil.StatusOf(err), FromErrorWithDataLocale(err, FieldErrors(err), LocaleFromRequest(r)))
+	}
+	return WriteStatus(w, r, errorutil.StatusOf(err), FromErrorLocale(err, LocaleFromRequest(r)))
+}
+
+// WriteData 写入成功响应
+func WriteData(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	return WriteStatus(w, r, http.StatusOK, FromErrorWithData(nil, data))
+}
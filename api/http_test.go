@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/log"
+)
+
+var errUserNotFound = errorutil.MustRegisterWithStatus(19404, http.StatusNotFound, "用户不存在", map[string]string{errorutil.LocaleEn: "user not found"})
+
+func TestWrite(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r = r.WithContext(context.WithValue(r.Context(), log.TRACEID, "trace-123"))
+	w := httptest.NewRecorder()
+
+	if err := Write(w, r, SuccessWithTypedData("成功", user{ID: 1, Name: "alice"})); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+	if w.Header().Get(TraceIDHeader) != "trace-123" || w.Header().Get(RequestIDHeader) != "trace-123" {
+		t.Errorf("Expected trace id headers, got %v", w.Header())
+	}
+
+	u, err := Decode[user](w.Body.Bytes())
+	if err != nil || u.Name != "alice" {
+		t.Errorf("Unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestWriteStatusFromCode(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+
+	response, _ := Error("用户不存在", 19404)
+	Write(w, r, response)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 from registered code, got %d", w.Code)
+	}
+	if w.Header().Get(RequestIDHeader) != "req-1" {
+		t.Errorf("Expected request id to be echoed, got %s", w.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+
+	WriteError(w, r, errUserNotFound.WithCause(errors.New("sql: no rows")))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	expected := `{"message":"user not found","code":19404}`
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
+
+func TestWriteNegotiation(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set("Accept", "application/xml;q=0.9, text/plain")
+	w := httptest.NewRecorder()
+
+	WriteData(w, r, map[string]int{"id": 1})
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+	expected := "code=0 message=success data={\"id\":1}\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, w.Body.String())
+	}
+
+	// 不支持的类型使用JSON
+	r.Header.Set("Accept", "application/xml")
+	w = httptest.NewRecorder()
+	WriteData(w, r, nil)
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/daozhonglee/go-util/errorutil"
+)
+
+// LocaleQueryKey 指定语言的查询参数，优先级高于 Accept-Language 请求头
+var LocaleQueryKey = "lang"
+
+// LocaleFromRequest 获取请求对应的语言，只返回 errorutil 中已注册的语言
+// 依次尝试查询参数 lang、Accept-Language 请求头，都不匹配时返回 errorutil.DefaultLocale
+func LocaleFromRequest(r *http.Request) string {
+	if r == nil {
+		return errorutil.DefaultLocale
+	}
+	if lang := r.URL.Query().Get(LocaleQueryKey); lang != "" {
+		if locale := errorutil.MatchLocale(lang); locale != "" {
+			return locale
+		}
+	}
+	for _, lang := range ParseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if locale := errorutil.MatchLocale(lang); locale != "" {
+			return locale
+		}
+	}
+	return errorutil.DefaultLocale
+}
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回语言列表
+// 例如 "zh-CN,zh;q=0.9,en;q=0.8" 返回 [zh-CN zh en]
+func ParseAcceptLanguage(header string) []string {
+	return parseQualityList(header)
+}
+
+// parseQualityList 解析带 q 权重的请求头（Accept、Accept-Language），按权重从高到低返回，忽略 q=0 的项
+func parseQualityList(header string) []string {
+	type item struct {
+		value string
+		q     float64
+	}
+
+	items := []item{}
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		value = strings.TrimSpace(value)
+		if value == "" || value == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		items = append(items, item{value: value, q: q})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].q > items[j].q })
+
+	result := make([]string, 0, len(items))
+	for _, i := range items {
+		result = append(result, i.value)
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/daozhonglee/go-util/errorutil"
+)
+
+var errOrderNotFound = errorutil.MustRegister(19001, "订单不存在", map[string]string{errorutil.LocaleEn: "order not found"})
+
+func TestParseAcceptLanguage(t *testing.T) {
+	langs := ParseAcceptLanguage("en;q=0.8, zh-CN,zh;q=0.9,fr;q=0")
+	expected := []string{"zh-CN", "zh", "en"}
+	if !reflect.DeepEqual(langs, expected) {
+		t.Errorf("Expected %v, got %v", expected, langs)
+	}
+}
+
+func TestLocaleFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/orders", nil)
+	if locale := LocaleFromRequest(r); locale != errorutil.DefaultLocale {
+		t.Errorf("Expected default locale, got %s", locale)
+	}
+
+	r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	if locale := LocaleFromRequest(r); locale != errorutil.LocaleEn {
+		t.Errorf("Expected en, got %s", locale)
+	}
+
+	// 查询参数优先
+	r = httptest.NewRequest("GET", "/orders?lang=zh-CN", nil)
+	r.Header.Set("Accept-Language", "en")
+	if locale := LocaleFromRequest(r); locale != errorutil.LocaleZhCN {
+		t.Errorf("Expected zh-CN, got %s", locale)
+	}
+}
+
+func TestFromErrorLocale(t *testing.T) {
+	response := FromErrorLocale(errOrderNotFound, errorutil.LocaleEn)
+	if response.Code != 19001 || response.Message != "order not found" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	response = FromErrorLocale(errOrderNotFound, errorutil.LocaleZhCN)
+	if response.Message != "订单不存在" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/daozhonglee/go-util/errorutil"
+)
+
+// 分页参数
+var (
+	DefaultPageLimit int64 = 20  // 未指定 limit 时使用
+	MaxPageLimit     int64 = 100 // limit 超出时截断
+)
+
+// CursorSecret 游标签名密钥，非空时游标带 HMAC-SHA256 签名，防止客户端篡改
+var CursorSecret []byte
+
+var (
+	ErrInvalidCursor    = errorutil.New(http.StatusBadRequest, "无效的分页游标")
+	ErrInvalidPageParam = errorutil.New(http.StatusBadRequest, "无效的分页参数")
+)
+
+// CursorPagination 游标分页信息，Next/Prev 为不透明的游标，没有更多数据时为空
+type CursorPagination struct {
+	Next    string `json:"next,omitempty"`
+	Prev    string `json:"prev,omitempty"`
+	Limit   int64  `json:"limit"`
+	HasMore bool   `json:"has_more"`
+}
+
+// CursorPageResponse 游标分页响应结构
+type CursorPageResponse struct {
+	Message string           `json:"message"`
+	Code    int              `json:"code"`
+	Data    interface{}      `json:"data"`
+	Page    CursorPagination `json:"pagination"`
+}
+
+// TypedCursorPageResponse 数据类型确定的游标分页响应结构
+type TypedCursorPageResponse[T any] struct {
+	Message string           `json:"message"`
+	Code    int              `json:"code"`
+	Data    T                `json:"data"`
+	Page    CursorPagination `json:"pagination"`
+}
+
+// SuccessWithCursorPage 创建游标分页成功响应
+func SuccessWithCursorPage(message string, data interface{}, page CursorPagination) *CursorPageResponse {
+	return &CursorPageResponse{
+		Message: message,
+		Data:    data,
+		Page:    page,
+	}
+}
+
+// SuccessWithTypedCursorPage 创建数据类型确定的游标分页成功响应
+func SuccessWithTypedCursorPage[T any](message string, data T, page CursorPagination) *TypedCursorPageResponse[T] {
+	return &TypedCursorPageResponse[T]{
+		Message: message,
+		Data:    data,
+		Page:    page,
+	}
+}
+
+func (r *CursorPageResponse) GetCode() int {
+	return r.Code
+}
+
+func (r *CursorPageResponse) GetMessage() string {
+	return r.Message
+}
+
+func (r *CursorPageResponse) GetData() interface{} {
+	return r.Data
+}
+
+func (r *TypedCursorPageResponse[T]) GetCode() int {
+	return r.Code
+}
+
+func (r *TypedCursorPageResponse[T]) GetMessage() string {
+	return r.Message
+}
+
+func (r *TypedCursorPageResponse[T]) GetData() interface{} {
+	return r.Data
+}
+
+type cursorPayload[K any] struct {
+	Key      K    `json:"k"`
+	Backward bool `json:"b,omitempty"`
+}
+
+// EncodeCursor 将排序键编码为游标，K 一般为包含排序字段的结构体，如 struct{ CreatedAt, ID int64 }
+// backward 为true表示向前翻页（上一页）
+func EncodeCursor[K any](key K, backward bool) (string, error) {
+	body, err := sonic.Marshal(cursorPayload[K]{Key: key, Backward: backward})
+	if err != nil {
+		return "", err
+	}
+	cursor := base64.RawURLEncoding.EncodeToString(body)
+	if len(CursorSecret) > 0 {
+		cursor += "." + base64.RawURLEncoding.EncodeToString(signCursor(body))
+	}
+	return cursor, nil
+}
+
+// DecodeCursor 解析游标，游标格式错误或签名不匹配时返回 ErrInvalidCursor
+func DecodeCursor[K any](cursor string) (key K, backward bool, err error) {
+	data, sig, signed := strings.Cut(cursor, ".")
+	body, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return key, false, ErrInvalidCursor.WithCause(err)
+	}
+	if len(CursorSecret) > 0 {
+		expected, err := base64.RawURLEncoding.DecodeString(sig)
+		if !signed || err != nil || !hmac.Equal(expected, signCursor(body)) {
+			return key, false, ErrInvalidCursor
+		}
+	}
+
+	var payload cursorPayload[K]
+	if err := sonic.Unmarshal(body, &payload); err != nil {
+		return key, false, ErrInvalidCursor.WithCause(err)
+	}
+	return payload.Key, payload.Backward, nil
+}
+
+func signCursor(body []byte) []byte {
+	mac := hmac.New(sha256.New, CursorSecret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// CursorDirection 游标分页请求的翻页方向
+type CursorDirection int
+
+const (
+	CursorFirst    CursorDirection = iota // 请求不带游标，查询第一页
+	CursorForward                         // 向后翻页（下一页）
+	CursorBackward                        // 向前翻页（上一页）
+)
+
+// DirectionOf 根据请求的游标和 DecodeCursor 解析出的 backward 返回翻页方向
+func DirectionOf(cursor string, backward bool) CursorDirection {
+	switch {
+	case cursor == "":
+		return CursorFirst
+	case backward:
+		return CursorBackward
+	default:
+		return CursorForward
+	}
+}
+
+// BuildCursorPage 根据查询结果构建游标分页
+// 查询时应多取一条（limit+1）用于判断是否还有更多数据，返回截断到 limit 的结果；limit<=0 时使用 DefaultPageLimit
+// 向后翻页时 HasMore 决定 Next，请求带游标时总是生成 Prev；
+// 向前翻页时 items 为按反向排序查询的结果，返回前会恢复为正常顺序，HasMore 决定 Prev，Next 总是生成
+func BuildCursorPage[T any, K any](items []T, limit int64, dir CursorDirection, keyOf func(T) K) ([]T, CursorPagination, error) {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	page := CursorPagination{Limit: limit}
+	if int64(len(items)) > limit {
+		items = items[:limit]
+		page.HasMore = true
+	}
+	if dir == CursorBackward {
+		reversed := make([]T, len(items))
+		for i, item := range items {
+			reversed[len(items)-1-i] = item
+		}
+		items = reversed
+	}
+	if len(items) == 0 {
+		return items, page, nil
+	}
+
+	hasNext, hasPrev := page.HasMore, dir != CursorFirst
+	if dir == CursorBackward {
+		hasNext, hasPrev = true, page.HasMore
+	}
+
+	var err error
+	if hasNext {
+		if page.Next, err = EncodeCursor(keyOf(items[len(items)-1]), false); err != nil {
+			return items, page, err
+		}
+	}
+	if hasPrev {
+		if page.Prev, err = EncodeCursor(keyOf(items[0]), true); err != nil {
+			return items, page, err
+		}
+	}
+	return items, page, nil
+}
+
+// PageParams 分页请求参数，同时支持 offset 和 cursor 两种方式
+type PageParams struct {
+	Offset int64
+	Limit  int64
+	Cursor string
+}
+
+// IsCursor 请求是否使用游标分页
+func (p PageParams) IsCursor() bool {
+	return p.Cursor != ""
+}
+
+// Pagination 返回 offset 分页信息
+func (p PageParams) Pagination(total int64) Pagination {
+	return Pagination{Offset: p.Offset, Limit: p.Limit, Total: total}
+}
+
+// ParsePageParams 从查询参数 offset、limit、cursor 解析分页参数
+// limit 未指定或<=0 时使用 DefaultPageLimit，超过 MaxPageLimit 时截断；offset 不能为负数
+func ParsePageParams(r *http.Request) (PageParams, error) {
+	query := r.URL.Query()
+	params := PageParams{Limit: DefaultPageLimit, Cursor: query.Get("cursor")}
+
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || offset < 0 {
+			return params, ErrInvalidPageParam.WithMessage("无效的分页参数: offset")
+		}
+		params.Offset = offset
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return params, ErrInvalidPageParam.WithMessage("无效的分页参数: limit")
+		}
+		params.Limit = clampLimit(limit)
+	}
+	return params, nil
+}
+
+func clampLimit(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return limit
+}
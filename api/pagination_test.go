@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type userKey struct {
+	CreatedAt int64 `json:"c"`
+	ID        int64 `json:"i"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	key := userKey{CreatedAt: 1700000000123, ID: 9007199254740993} // 超过 float64 精度的ID
+	cursor, err := EncodeCursor(key, true)
+	if err != nil {
+		t.Fatalf("EncodeCursor failed: %v", err)
+	}
+
+	decoded, backward, err := DecodeCursor[userKey](cursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor failed: %v", err)
+	}
+	if decoded != key || !backward {
+		t.Errorf("Expected %+v backward, got %+v %v", key, decoded, backward)
+	}
+
+	if _, _, err := DecodeCursor[userKey]("!!!"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestCursorSigned(t *testing.T) {
+	CursorSecret = []byte("secret")
+	defer func() { CursorSecret = nil }()
+
+	cursor, _ := EncodeCursor(userKey{ID: 1}, false)
+	if _, _, err := DecodeCursor[userKey](cursor); err != nil {
+		t.Fatalf("Expected signed cursor to decode, got %v", err)
+	}
+
+	// 篡改游标内容
+	forged, _ := EncodeCursor(userKey{ID: 2}, false)
+	data, _, _ := strings.Cut(forged, ".")
+	_, sig, _ := strings.Cut(cursor, ".")
+	if _, _, err := DecodeCursor[userKey](data + "." + sig); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected forged cursor to be rejected, got %v", err)
+	}
+
+	// 未签名的游标
+	CursorSecret = nil
+	unsigned, _ := EncodeCursor(userKey{ID: 1}, false)
+	CursorSecret = []byte("secret")
+	if _, _, err := DecodeCursor[userKey](unsigned); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected unsigned cursor to be rejected, got %v", err)
+	}
+}
+
+func TestBuildCursorPage(t *testing.T) {
+	users := []user{{ID: 1}, {ID: 2}, {ID: 3}}
+	keyOf := func(u user) userKey { return userKey{ID: u.ID} }
+
+	items, page, err := BuildCursorPage(users, 2, CursorFirst, keyOf)
+	if err != nil {
+		t.Fatalf("BuildCursorPage failed: %v", err)
+	}
+	if len(items) != 2 || !page.HasMore || page.Next == "" || page.Prev != "" {
+		t.Errorf("Unexpected page: %d items, %+v", len(items), page)
+	}
+	next, _, _ := DecodeCursor[userKey](page.Next)
+	if next.ID != 2 {
+		t.Errorf("Expected next cursor at ID 2, got %+v", next)
+	}
+
+	items, page, _ = BuildCursorPage(users[2:], 2, CursorForward, keyOf)
+	if len(items) != 1 || page.HasMore || page.Next != "" || page.Prev == "" {
+		t.Errorf("Unexpected last page: %d items, %+v", len(items), page)
+	}
+
+	// limit 为负数时使用默认值，不能越界
+	items, page, _ = BuildCursorPage(users, -1, CursorFirst, keyOf)
+	if len(items) != 3 || page.HasMore || page.Limit != DefaultPageLimit {
+		t.Errorf("Unexpected page for negative limit: %d items, %+v", len(items), page)
+	}
+}
+
+func TestBuildCursorPageBackward(t *testing.T) {
+	keyOf := func(u user) userKey { return userKey{ID: u.ID} }
+
+	// 从 ID 4 向前翻页，按 ID 倒序多取一条
+	items, page, err := BuildCursorPage([]user{{ID: 3}, {ID: 2}, {ID: 1}}, 2, CursorBackward, keyOf)
+	if err != nil {
+		t.Fatalf("BuildCursorPage failed: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != 2 || items[1].ID != 3 {
+		t.Errorf("Expected items [2 3], got %+v", items)
+	}
+	if !page.HasMore || page.Prev == "" || page.Next == "" {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+	prev, backward, _ := DecodeCursor[userKey](page.Prev)
+	if prev.ID != 2 || !backward {
+		t.Errorf("Expected backward prev cursor at ID 2, got %+v %v", prev, backward)
+	}
+	next, backward, _ := DecodeCursor[userKey](page.Next)
+	if next.ID != 3 || backward {
+		t.Errorf("Expected forward next cursor at ID 3, got %+v %v", next, backward)
+	}
+
+	// 已经翻到第一页：没有 Prev，但仍然有 Next
+	items, page, _ = BuildCursorPage([]user{{ID: 1}}, 2, CursorBackward, keyOf)
+	if len(items) != 1 || page.HasMore || page.Prev != "" || page.Next == "" {
+		t.Errorf("Unexpected first page: %d items, %+v", len(items), page)
+	}
+
+	if dir := DirectionOf(page.Next, false); dir != CursorForward {
+		t.Errorf("Expected CursorForward, got %v", dir)
+	}
+	if dir := DirectionOf("", false); dir != CursorFirst {
+		t.Errorf("Expected CursorFirst, got %v", dir)
+	}
+}
+
+func TestParsePageParams(t *testing.T) {
+	params, err := ParsePageParams(httptest.NewRequest("GET", "/users", nil))
+	if err != nil || params.Limit != DefaultPageLimit || params.Offset != 0 || params.IsCursor() {
+		t.Errorf("Unexpected default params: %+v, %v", params, err)
+	}
+
+	params, _ = ParsePageParams(httptest.NewRequest("GET", "/users?offset=20&limit=1000", nil))
+	if params.Offset != 20 || params.Limit != MaxPageLimit {
+		t.Errorf("Expected limit clamped to %d, got %+v", MaxPageLimit, params)
+	}
+	if page := params.Pagination(35); page.Total != 35 || page.Offset != 20 {
+		t.Errorf("Unexpected pagination: %+v", page)
+	}
+
+	params, _ = ParsePageParams(httptest.NewRequest("GET", "/users?cursor=abc&limit=5", nil))
+	if !params.IsCursor() || params.Limit != 5 {
+		t.Errorf("Unexpected cursor params: %+v", params)
+	}
+
+	if _, err := ParsePageParams(httptest.NewRequest("GET", "/users?offset=-1", nil)); !errors.Is(err, ErrInvalidPageParam) {
+		t.Errorf("Expected ErrInvalidPageParam, got %v", err)
+	}
+}
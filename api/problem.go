@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/bytedance/sonic"
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/log"
+)
+
+// MediaTypeProblemJSON RFC 9457 Problem Details 的媒体类型
+// Accept 请求头选择该类型时，错误响应（HTTP状态码>=400）按 Problem Details 格式输出，成功响应仍为JSON
+const MediaTypeProblemJSON = "application/problem+json"
+
+// ProblemTypeBase Problem 的 type 前缀，为空时 type 为 about:blank
+// 设置后 type 为前缀加业务错误码，如 https://example.com/errors/19404，可指向错误码说明文档
+var ProblemTypeBase = ""
+
+// Problem RFC 9457 Problem Details
+// 扩展字段：code 为业务错误码，errors 为字段错误列表，data 为其他错误详情，trace_id 为链路ID
+type Problem struct {
+	Type     string       `json:"type,omitempty"`
+	Title    string       `json:"title,omitempty"`
+	Status   int          `json:"status,omitempty"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     int          `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	Data     interface{}  `json:"data,omitempty"`
+	TraceID  string       `json:"trace_id,omitempty"`
+}
+
+func (p *Problem) GetCode() int {
+	return p.Code
+}
+
+func (p *Problem) GetMessage() string {
+	return p.Detail
+}
+
+func (p *Problem) GetData() interface{} {
+	if p.Errors != nil {
+		return p.Errors
+	}
+	return p.Data
+}
+
+// NewProblem 将响应转换为 Problem，status 为0时由业务错误码推导
+// 响应数据为 []FieldError 时放在 errors 扩展字段中，其他数据放在 data 扩展字段中
+// v 本身是 *Problem 时返回其副本，不会修改调用方的值
+func NewProblem(v Envelope, status int) *Problem {
+	if src, ok := v.(*Problem); ok {
+		p := *src
+		if status == 0 {
+			status = p.Status
+		}
+		if status == 0 {
+			status = StatusOf(p.Code)
+		}
+		if p.Title == "" || p.Title == http.StatusText(p.Status) {
+			p.Title = http.StatusText(status)
+		}
+		p.Status = status
+		return &p
+	}
+	if status == 0 {
+		status = StatusOf(v.GetCode())
+	}
+
+	p := &Problem{
+		Type:   problemType(v.GetCode()),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: v.GetMessage(),
+		Code:   v.GetCode(),
+	}
+	if d, ok := v.(interface{ GetData() interface{} }); ok {
+		switch data := d.GetData().(type) {
+		case nil:
+		case []FieldError:
+			p.Errors = data
+		default:
+			p.Data = data
+		}
+	}
+	return p
+}
+
+// ProblemFromError 将错误转换为 Problem，提示信息使用 locale 对应的语言
+// 错误链中包含 *errorutil.MultiError 时，errors 为字段错误列表
+func ProblemFromError(err error, locale string) *Problem {
+	var multi *errorutil.MultiError
+	if errors.As(err, &multi) {
+		return NewProblem(FromErrorWithDataLocale(err, FieldErrors(err), locale), errorutil.StatusOf(err))
+	}
+	return NewProblem(FromErrorLocale(err, locale), errorutil.StatusOf(err))
+}
+
+func problemType(code int) string {
+	if ProblemTypeBase == "" {
+		return "about:blank"
+	}
+	return ProblemTypeBase + strconv.Itoa(code)
+}
+
+// Response 将 Problem 转换回基础响应结构
+func (p *Problem) Response() *Response {
+	return &Response{
+		Message: p.Detail,
+		Code:    p.Code,
+	}
+}
+
+// DataResponse 将 Problem 转换回数据响应结构，data 为字段错误列表或其他错误详情
+func (p *Problem) DataResponse() *DataResponse {
+	return &DataResponse{
+		Message: p.Detail,
+		Code:    p.Code,
+		Data:    p.GetData(),
+	}
+}
+
+// ParseProblem 解析 Problem Details 格式的响应体
+// 没有 code 扩展字段的第三方 Problem 使用 status 作为业务错误码，没有 detail 时使用 title 作为提示信息
+func ParseProblem(body []byte) (*Problem, error) {
+	var raw struct {
+		Problem
+		Code *int `json:"code"`
+	}
+	if err := sonic.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("api: invalid problem details: %w", err)
+	}
+
+	p := raw.Problem
+	if raw.Code != nil {
+		p.Code = *raw.Code
+	} else {
+		p.Code = p.Status
+	}
+	if p.Detail == "" {
+		p.Detail = p.Title
+	}
+	return &p, nil
+}
+
+// ParseResponse 按 Content-Type 解析响应体，Problem Details 和普通JSON响应都转换为 DataResponse
+func ParseResponse(contentType string, body []byte) (*DataResponse, error) {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == MediaTypeProblemJSON {
+		p, err := ParseProblem(body)
+		if err != nil {
+			return nil, err
+		}
+		return p.DataResponse(), nil
+	}
+
+	var response DataResponse
+	if err := sonic.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("api: invalid response: %w", err)
+	}
+	return &response, nil
+}
+
+// WriteProblem 将错误按 Problem Details 格式写入 w，不受 Accept 请求头影响
+// 用于面向第三方的接口，提示信息使用请求对应的语言；err 为nil时按未知错误输出500
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) error {
+	if err == nil {
+		return writeProblem(w, r, NewProblem(&Response{Code: errorutil.CodeUnknown, Message: DefaultErrorMessage}, http.StatusInternalServerError))
+	}
+	return writeProblem(w, r, ProblemFromError(err, LocaleFromRequest(r)))
+}
+
+// WriteProblemStatus 将响应按 Problem Details 格式写入 w，status 为0时由业务错误码推导
+func WriteProblemStatus(w http.ResponseWriter, r *http.Request, status int, v Envelope) error {
+	return writeProblem(w, r, NewProblem(v, status))
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, p *Problem) error {
+	if r != nil {
+		if p.Instance == "" {
+			p.Instance = r.URL.Path
+		}
+		if v := r.Context().Value(log.TRACEID); v != nil && p.TraceID == "" {
+			p.TraceID = fmt.Sprint(v)
+		}
+	}
+	writeHeader(w, r, MediaTypeProblemJSON)
+	w.WriteHeader(p.Status)
+	return encodeJSON(w, p)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/log"
+)
+
+func TestWriteProblem(t *testing.T) {
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set("Accept-Language", "en")
+	r = r.WithContext(context.WithValue(r.Context(), log.TRACEID, "trace-123"))
+	w := httptest.NewRecorder()
+
+	WriteProblem(w, r, errUserNotFound.WithCause(errors.New("sql: no rows")))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+	expected := `{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found","instance":"/users/1","code":19404,"trace_id":"trace-123"}`
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
+
+func TestWriteErrorProblemNegotiation(t *testing.T) {
+	r := httptest.NewRequest("POST", "/users", nil)
+	r.Header.Set("Accept", "application/problem+json, application/json;q=0.5")
+	w := httptest.NewRecorder()
+
+	var errs errorutil.MultiError
+	errs.Add("name", errors.New("不能为空"))
+	WriteError(w, r, ErrInvalidParam.WithCause(&errs))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+
+	response, err := ParseResponse(w.Header().Get("Content-Type"), w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("ParseResponse failed: %v", err)
+	}
+	if response.Code != 400 || response.Message != "参数错误" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	items, ok := response.Data.([]FieldError)
+	if !ok || len(items) != 1 || items[0].Field != "name" {
+		t.Errorf("Unexpected field errors: %#v", response.Data)
+	}
+
+	// 成功响应不使用 Problem Details
+	w = httptest.NewRecorder()
+	WriteData(w, r, map[string]int{"id": 1})
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+}
+
+func TestProblemRoundTrip(t *testing.T) {
+	ProblemTypeBase = "https://example.com/errors/"
+	defer func() { ProblemTypeBase = "" }()
+
+	response, _ := ErrorWithData("余额不足", 1402, map[string]interface{}{"balance": float64(10)})
+	p := NewProblem(response, 0)
+	if p.Type != "https://example.com/errors/1402" || p.Status != http.StatusInternalServerError {
+		t.Errorf("Unexpected problem: %+v", p)
+	}
+
+	body, _ := sonic.Marshal(p)
+	parsed, err := ParseProblem(body)
+	if err != nil {
+		t.Fatalf("ParseProblem failed: %v", err)
+	}
+	back := parsed.DataResponse()
+	if back.Code != 1402 || back.Message != "余额不足" || back.Data.(map[string]interface{})["balance"] != float64(10) {
+		t.Errorf("Unexpected round trip: %+v", back)
+	}
+	if r := parsed.Response(); r.Code != 1402 || r.Message != "余额不足" {
+		t.Errorf("Unexpected response: %+v", r)
+	}
+}
+
+func TestParseThirdPartyProblem(t *testing.T) {
+	body := []byte(`{"type":"https://example.net/out-of-credit","title":"You do not have enough credit.","status":403}`)
+	p, err := ParseProblem(body)
+	if err != nil {
+		t.Fatalf("ParseProblem failed: %v", err)
+	}
+	if r := p.Response(); r.Code != 403 || r.Message != "You do not have enough credit." {
+		t.Errorf("Unexpected response: %+v", r)
+	}
+
+	if _, err := ParseProblem([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid body")
+	}
+}
+
+func TestWriteProblemStatusCopiesProblem(t *testing.T) {
+	r := httptest.NewRequest("GET", "/orders/1", nil)
+	p := &Problem{Detail: "order not found", Code: 19404}
+
+	// status 为0时由业务错误码推导，不能修改调用方的值
+	w := httptest.NewRecorder()
+	if err := WriteProblemStatus(w, r, 0, p); err != nil {
+		t.Fatalf("WriteProblemStatus failed: %v", err)
+	}
+	if w.Code != StatusOf(19404) {
+		t.Errorf("Expected status %d, got %d", StatusOf(19404), w.Code)
+	}
+	if p.Status != 0 || p.Instance != "" || p.Title != "" {
+		t.Errorf("Caller's problem was mutated: %+v", p)
+	}
+
+	// 显式的 status 覆盖 Problem 中的状态码
+	p.Status = http.StatusNotFound
+	p.Title = http.StatusText(http.StatusNotFound)
+	w = httptest.NewRecorder()
+	WriteProblemStatus(w, r, http.StatusGone, p)
+	if w.Code != http.StatusGone {
+		t.Errorf("Expected status 410, got %d", w.Code)
+	}
+	parsed, err := ParseProblem(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("ParseProblem failed: %v", err)
+	}
+	if parsed.Status != http.StatusGone || parsed.Title != "Gone" || parsed.Instance != "/orders/1" {
+		t.Errorf("Unexpected problem: %+v", parsed)
+	}
+	if p.Status != http.StatusNotFound {
+		t.Errorf("Caller's problem was mutated: %+v", p)
+	}
+}
+
+func TestWriteProblemNilError(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteProblem(w, httptest.NewRequest("GET", "/", nil), nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	parsed, err := ParseProblem(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("ParseProblem failed: %v", err)
+	}
+	if parsed.Code != errorutil.CodeUnknown || parsed.Status != http.StatusInternalServerError {
+		t.Errorf("Unexpected problem: %+v", parsed)
+	}
+}
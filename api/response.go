@@ -2,7 +2,7 @@
 package api
 
 import (
-	"errors"
+	"github.com/daozhonglee/go-util/errorutil"
 )
 
 // Success 创建成功响应 (code=0)
@@ -37,7 +37,7 @@ func NewResponse(message string, code int) (*Response, error) {
 		Code:    code,
 	}
 	if code != 0 {
-		return response, errors.New(message)
+		return response, errorutil.New(code, message)
 	}
 	return response, nil
 }
@@ -50,7 +50,7 @@ func NewDataResponse(message string, code int, data interface{}) (*DataResponse,
 		Data:    data,
 	}
 	if code != 0 {
-		return response, errors.New(message)
+		return response, errorutil.New(code, message)
 	}
 	return response, nil
 }
@@ -64,7 +64,7 @@ func NewPageResponse(message string, code int, data interface{}, page Pagination
 		Page:    page,
 	}
 	if code != 0 {
-		return response, errors.New(message)
+		return response, errorutil.New(code, message)
 	}
 	return response, nil
 }
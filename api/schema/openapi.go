@@ -0,0 +1,268 @@
+package schema
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/daozhonglee/go-util/api"
+)
+
+// OpenAPI OpenAPI 3.0 文档
+type OpenAPI struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info 文档基本信息
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 小写的 HTTP 方法 -> 接口
+type PathItem map[string]*OperationObject
+
+// OperationObject 接口描述
+type OperationObject struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	OperationID string                     `json:"operationId,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []Parameter                `json:"parameters,omitempty"`
+	RequestBody *RequestBody               `json:"requestBody,omitempty"`
+	Responses   map[string]*ResponseObject `json:"responses"`
+}
+
+// Parameter 路径或查询参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody 请求体，媒体类型 -> 内容
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType 某个媒体类型的内容
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// ResponseObject 响应描述
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Components 可复用的组件，具名结构体的 Schema 放在 Schemas 中
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Operation 接口声明
+//   - Request 为请求结构体，字段标签与 api.Bind 一致：path、query 标签生成参数，form 标签生成表单请求体，
+//     其余带 json 标签的字段生成 JSON 请求体
+//   - Response 为成功时的响应结构，一般为 api 包中的响应结构，如 api.TypedResponse[Order]{}
+//   - Errors 为错误响应，HTTP状态码 -> 响应结构
+//     未声明时使用 api.Response 作为 default 响应，有 Request 时额外声明携带字段错误列表的 400 响应
+type Operation struct {
+	Method      string
+	Path        string // 路径参数使用 {name}，与 net/http 路由一致
+	Summary     string
+	Description string
+	Tags        []string
+	Request     interface{}
+	Response    interface{}
+	Errors      map[int]interface{}
+}
+
+// Spec 接口文档，Add 声明接口后通过 JSON、Handler 输出 OpenAPI 文档
+type Spec struct {
+	mu   sync.Mutex
+	info Info
+	ops  []Operation
+}
+
+// NewSpec 创建接口文档
+func NewSpec(title string, version string) *Spec {
+	return &Spec{info: Info{Title: title, Version: version}}
+}
+
+// Add 声明接口
+func (s *Spec) Add(ops ...Operation) *Spec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ops = append(s.ops, ops...)
+	return s
+}
+
+// Document 生成 OpenAPI 文档
+func (s *Spec) Document() *OpenAPI {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := NewGenerator("#/components/schemas/")
+	doc := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info:    s.info,
+		Paths:   map[string]PathItem{},
+	}
+	for _, op := range s.ops {
+		item, ok := doc.Paths[op.Path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = buildOperation(g, op)
+	}
+	doc.Components.Schemas = g.Definitions()
+	return doc
+}
+
+// JSON 输出 OpenAPI 文档，map 按 key 排序保证输出稳定
+func (s *Spec) JSON() ([]byte, error) {
+	return sonic.ConfigStd.MarshalIndent(s.Document(), "", "  ")
+}
+
+// Handler 输出 OpenAPI 文档的 http.Handler，如 http.Handle("/openapi.json", spec.Handler())
+func (s *Spec) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := s.JSON()
+		if err != nil {
+			api.WriteError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(body)
+	})
+}
+
+func buildOperation(g *Generator, op Operation) *OperationObject {
+	result := &OperationObject{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: operationID(op),
+		Tags:        op.Tags,
+		Responses:   map[string]*ResponseObject{},
+	}
+
+	if op.Request != nil {
+		result.Parameters, result.RequestBody = buildRequest(g, op.Method, reflect.TypeOf(op.Request))
+	}
+
+	result.Responses["200"] = jsonResponse(g, "成功", op.Response)
+	codes := make([]int, 0, len(op.Errors))
+	for code := range op.Errors {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		result.Responses[strconv.Itoa(code)] = jsonResponse(g, http.StatusText(code), op.Errors[code])
+	}
+	if len(op.Errors) == 0 {
+		if op.Request != nil {
+			result.Responses["400"] = jsonResponse(g, "参数错误", api.TypedResponse[[]api.FieldError]{})
+		}
+		result.Responses["default"] = jsonResponse(g, "错误", api.Response{})
+	}
+	return result
+}
+
+func jsonResponse(g *Generator, description string, v interface{}) *ResponseObject {
+	if v == nil {
+		return &ResponseObject{Description: description}
+	}
+	return &ResponseObject{
+		Description: description,
+		Content:     map[string]MediaType{api.MediaTypeJSON: {Schema: g.For(v)}},
+	}
+}
+
+// buildRequest 按 api.Bind 的标签规则拆分请求结构体
+func buildRequest(g *Generator, method string, t reflect.Type) ([]Parameter, *RequestBody) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, &RequestBody{Content: map[string]MediaType{api.MediaTypeJSON: {Schema: g.Type(t)}}}
+	}
+
+	params := []Parameter{}
+	form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	body := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	collectRequestFields(g, t, &params, form, body)
+
+	var requestBody *RequestBody
+	if method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete {
+		content := map[string]MediaType{}
+		if len(body.Properties) > 0 {
+			content[api.MediaTypeJSON] = MediaType{Schema: body}
+		}
+		if len(form.Properties) > 0 {
+			content["application/x-www-form-urlencoded"] = MediaType{Schema: form}
+		}
+		if len(content) > 0 {
+			requestBody = &RequestBody{
+				Required: len(body.Required) > 0 || len(form.Required) > 0,
+				Content:  content,
+			}
+		}
+	}
+	return params, requestBody
+}
+
+func collectRequestFields(g *Generator, t reflect.Type, params *[]Parameter, form *Schema, body *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectRequestFields(g, field.Type, params, form, body)
+			continue
+		}
+
+		fs, required := g.Field(field)
+		if name := tagName(field, "path"); name != "" {
+			*params = append(*params, Parameter{Name: name, In: "path", Required: true, Description: fs.Description, Schema: fs})
+		} else if name := tagName(field, "query"); name != "" {
+			*params = append(*params, Parameter{Name: name, In: "query", Required: required, Description: fs.Description, Schema: fs})
+		} else if name := tagName(field, "form"); name != "" {
+			form.Properties[name] = fs
+			if required {
+				form.Required = append(form.Required, name)
+			}
+		} else if name, skip := jsonName(field); !skip && name != "" {
+			body.Properties[name] = fs
+			if required {
+				body.Required = append(body.Required, name)
+			}
+		}
+	}
+}
+
+func tagName(field reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// operationID 由方法和路径生成，如 POST /users/{user_id}/orders -> post_users_user_id_orders
+func operationID(op Operation) string {
+	return strings.Trim(nonIdentRe.ReplaceAllString(strings.ToLower(op.Method)+"_"+op.Path, "_"), "_")
+}
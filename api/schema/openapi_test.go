@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daozhonglee/go-util/api"
+	"github.com/daozhonglee/go-util/json"
+)
+
+type order struct {
+	ID     int64  `json:"id"`
+	Amount int64  `json:"amount"`
+	Status string `json:"status" validate:"enum=paid|refunded"`
+}
+
+type createOrderRequest struct {
+	UserID int64  `path:"user_id" description:"用户ID"`
+	DryRun bool   `query:"dry_run"`
+	Amount int64  `json:"amount" validate:"required,min=1"`
+	Remark string `json:"remark" validate:"max=200"`
+}
+
+type listOrderRequest struct {
+	UserID int64  `path:"user_id"`
+	Status string `query:"status" validate:"required,enum=paid|refunded"`
+}
+
+type uploadRequest struct {
+	Name string `form:"name" validate:"required"`
+}
+
+func newTestSpec() *Spec {
+	return NewSpec("订单服务", "1.0.0").Add(
+		Operation{
+			Method:   http.MethodPost,
+			Path:     "/users/{user_id}/orders",
+			Summary:  "创建订单",
+			Tags:     []string{"order"},
+			Request:  createOrderRequest{},
+			Response: api.TypedResponse[order]{},
+		},
+		Operation{
+			Method:   http.MethodGet,
+			Path:     "/users/{user_id}/orders",
+			Request:  &listOrderRequest{},
+			Response: api.TypedPageResponse[[]order]{},
+			Errors:   map[int]interface{}{http.StatusNotFound: api.Response{}},
+		},
+		Operation{
+			Method:  http.MethodPut,
+			Path:    "/files",
+			Request: uploadRequest{},
+		},
+	)
+}
+
+func TestSpecDocument(t *testing.T) {
+	doc := newTestSpec().Document()
+	if doc.OpenAPI != "3.0.3" || doc.Info.Title != "订单服务" {
+		t.Fatalf("doc = %+v", doc)
+	}
+
+	create := doc.Paths["/users/{user_id}/orders"]["post"]
+	if create.OperationID != "post_users_user_id_orders" {
+		t.Errorf("operationId = %q", create.OperationID)
+	}
+	if len(create.Parameters) != 2 {
+		t.Fatalf("parameters = %+v", create.Parameters)
+	}
+	if p := create.Parameters[0]; p.Name != "user_id" || p.In != "path" || !p.Required || p.Description != "用户ID" {
+		t.Errorf("path parameter = %+v", p)
+	}
+	if p := create.Parameters[1]; p.Name != "dry_run" || p.In != "query" || p.Required || p.Schema.Type != "boolean" {
+		t.Errorf("query parameter = %+v", p)
+	}
+	body := create.RequestBody.Content[api.MediaTypeJSON].Schema
+	if !create.RequestBody.Required || !reflect.DeepEqual(body.Required, []string{"amount"}) || len(body.Properties) != 2 {
+		t.Errorf("request body = %+v", body)
+	}
+	if got := create.Responses["200"].Content[api.MediaTypeJSON].Schema.Ref; got != "#/components/schemas/TypedResponse_order" {
+		t.Errorf("200 ref = %q", got)
+	}
+	if got := create.Responses["400"].Content[api.MediaTypeJSON].Schema.Ref; got != "#/components/schemas/TypedResponse_List_FieldError" {
+		t.Errorf("400 ref = %q", got)
+	}
+	if got := create.Responses["default"].Content[api.MediaTypeJSON].Schema.Ref; got != "#/components/schemas/Response" {
+		t.Errorf("default ref = %q", got)
+	}
+
+	list := doc.Paths["/users/{user_id}/orders"]["get"]
+	if list.RequestBody != nil {
+		t.Errorf("GET should not have request body: %+v", list.RequestBody)
+	}
+	if p := list.Parameters[1]; !p.Required || len(p.Schema.Enum) != 2 {
+		t.Errorf("status parameter = %+v", p)
+	}
+	if _, ok := list.Responses["404"]; !ok {
+		t.Errorf("responses = %v", list.Responses)
+	}
+	if _, ok := list.Responses["default"]; ok {
+		t.Error("default response should not be added when Errors is declared")
+	}
+
+	upload := doc.Paths["/files"]["put"]
+	form := upload.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+	if !reflect.DeepEqual(form.Required, []string{"name"}) {
+		t.Errorf("form = %+v", form)
+	}
+	if _, ok := upload.RequestBody.Content[api.MediaTypeJSON]; ok {
+		t.Error("form request should not have JSON body")
+	}
+
+	for _, name := range []string{"order", "TypedResponse_order", "TypedPageResponse_List_order", "Pagination", "FieldError", "Response"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("component %s missing, got %v", name, reflect.ValueOf(doc.Components.Schemas).MapKeys())
+		}
+	}
+}
+
+func TestSpecHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestSpec().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("status = %d, header = %v", rec.Code, rec.Header())
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("openapi = %v", doc["openapi"])
+	}
+
+	first, _ := newTestSpec().JSON()
+	second, _ := newTestSpec().JSON()
+	if string(first) != string(second) {
+		t.Error("JSON output should be stable")
+	}
+}
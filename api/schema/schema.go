@@ -0,0 +1,306 @@
+// Package schema 提供请求/响应结构的 JSON Schema 及 OpenAPI 3 文档生成，规范见 schema-spec.md
+package schema
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema JSON Schema（OpenAPI 3.0 子集）
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	MinItems             *int64             `json:"minItems,omitempty"`
+	MaxItems             *int64             `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// chinese_id 规则对应的正则，与 id.ValidateChinese 一致
+const chineseIDPattern = `^\d{17}[\dxX]$`
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generator 通过反射生成 Schema，具名结构体生成一次后以 $ref 引用，支持递归类型
+type Generator struct {
+	refPrefix string
+	defs      map[string]*Schema
+	names     map[reflect.Type]string
+	used      map[string]reflect.Type
+}
+
+// NewGenerator 创建生成器，refPrefix 为 $ref 前缀，如 "#/components/schemas/"
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{
+		refPrefix: refPrefix,
+		defs:      map[string]*Schema{},
+		names:     map[reflect.Type]string{},
+		used:      map[string]reflect.Type{},
+	}
+}
+
+// Definitions 返回已生成的具名结构体 Schema
+func (g *Generator) Definitions() map[string]*Schema {
+	return g.defs
+}
+
+// For 生成 v 的类型对应的 Schema
+func (g *Generator) For(v interface{}) *Schema {
+	return g.Type(reflect.TypeOf(v))
+}
+
+// JSONSchema 生成 v 的独立 JSON Schema，具名结构体放在 $defs 中
+func JSONSchema(v interface{}) *Schema {
+	g := NewGenerator("#/$defs/")
+	s := g.For(v)
+	if len(g.defs) > 0 {
+		root := *s
+		if root.Ref != "" {
+			root = *g.defs[strings.TrimPrefix(root.Ref, g.refPrefix)]
+		}
+		root.Defs = g.defs
+		return &root
+	}
+	return s
+}
+
+// Type 生成类型 t 对应的 Schema
+func (g *Generator) Type(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.Type(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.Type(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if name, ok := g.names[t]; ok {
+			return &Schema{Ref: g.refPrefix + name}
+		}
+		name := g.typeName(t)
+		g.names[t] = name
+		g.defs[name] = &Schema{} // 占位，支持递归引用
+		*g.defs[name] = *g.structSchema(t)
+		return &Schema{Ref: g.refPrefix + name}
+	default:
+		// interface{} 等任意类型
+		return &Schema{}
+	}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fs, required := g.Field(field)
+		s.Properties[name] = fs
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// Field 生成结构体字段的 Schema，应用 validate、description 标签，返回字段是否必填
+func (g *Generator) Field(field reflect.StructField) (*Schema, bool) {
+	s := g.Type(field.Type)
+	required := false
+
+	rules := parseRules(field.Tag.Get("validate"))
+	description := field.Tag.Get("description")
+	if s.Ref != "" {
+		// $ref 不能与其他关键字并列（OpenAPI 3.0），只保留必填信息
+		for _, rule := range rules {
+			if rule == "required" {
+				required = true
+			}
+		}
+		return s, required
+	}
+
+	s.Description = description
+	if field.Type.Kind() == reflect.Pointer {
+		s.Nullable = true
+	}
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			applyRange(s, name == "min", arg)
+		case "enum":
+			for _, option := range strings.Split(arg, "|") {
+				s.Enum = append(s.Enum, enumValue(s.Type, option))
+			}
+		case "chinese_id":
+			s.Pattern = chineseIDPattern
+		case "regex":
+			s.Pattern = arg
+		}
+	}
+	return s, required
+}
+
+func applyRange(s *Schema, isMin bool, arg string) {
+	f, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return
+	}
+	n := int64(f)
+	switch s.Type {
+	case "integer", "number":
+		if isMin {
+			s.Minimum = &f
+		} else {
+			s.Maximum = &f
+		}
+	case "string":
+		if isMin {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+	case "array":
+		if isMin {
+			s.MinItems = &n
+		} else {
+			s.MaxItems = &n
+		}
+	}
+}
+
+func enumValue(typ string, option string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(option, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(option, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(option); err == nil {
+			return b
+		}
+	}
+	return option
+}
+
+// parseRules 与 api.Validate 的规则格式一致，regex 必须是最后一条规则
+func parseRules(tag string) []string {
+	rules := []string{}
+	if tag == "-" {
+		return rules
+	}
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func jsonName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	return name, false
+}
+
+var pkgPathRe = regexp.MustCompile(`(?:[\w.\-]+/)*\w+\.`)
+var nonIdentRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// typeName 生成组件名，泛型参数去掉包路径，如 TypedResponse[[]api.user] -> TypedResponse_List_user
+// 不同包中的同名类型追加序号区分
+func (g *Generator) typeName(t reflect.Type) string {
+	name := pkgPathRe.ReplaceAllString(t.Name(), "")
+	name = strings.ReplaceAll(name, "[]", "List_")
+	name = strings.Trim(nonIdentRe.ReplaceAllString(name, "_"), "_")
+
+	unique := name
+	for i := 2; ; i++ {
+		if other, ok := g.used[unique]; !ok || other == t {
+			break
+		}
+		unique = name + strconv.Itoa(i)
+	}
+	g.used[unique] = t
+	return unique
+}
@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/daozhonglee/go-util/api"
+)
+
+type address struct {
+	City string `json:"city" validate:"required,max=32"`
+}
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type profile struct {
+	ID        int64          `json:"id" validate:"required,min=1" description:"用户ID"`
+	Name      string         `json:"name" validate:"required,min=2,max=20"`
+	Gender    string         `json:"gender" validate:"enum=male|female"`
+	Level     int            `json:"level" validate:"enum=1|2|3"`
+	IDCard    string         `json:"id_card" validate:"chinese_id"`
+	Phone     string         `json:"phone" validate:"regex=^1\\d{10}$"`
+	Nickname  *string        `json:"nickname"`
+	Tags      []string       `json:"tags" validate:"max=5"`
+	Extra     map[string]int `json:"extra"`
+	Address   address        `json:"address" validate:"required"`
+	CreatedAt time.Time      `json:"created_at"`
+	Avatar    []byte         `json:"avatar"`
+	Secret    string         `json:"-"`
+	internal  string
+	Meta      map[string]string `json:"meta,omitempty"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema(profile{})
+	if s.Type != "object" || s.Ref != "" {
+		t.Fatalf("root = %+v", s)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"id", "name", "address"}) {
+		t.Errorf("required = %v", s.Required)
+	}
+	if _, ok := s.Properties["Secret"]; ok {
+		t.Error("json:\"-\" field should be skipped")
+	}
+	if _, ok := s.Properties["internal"]; ok {
+		t.Error("unexported field should be skipped")
+	}
+
+	id := s.Properties["id"]
+	if id.Type != "integer" || id.Format != "int64" || *id.Minimum != 1 || id.Description != "用户ID" {
+		t.Errorf("id = %+v", id)
+	}
+	name := s.Properties["name"]
+	if *name.MinLength != 2 || *name.MaxLength != 20 {
+		t.Errorf("name = %+v", name)
+	}
+	if got := s.Properties["gender"].Enum; !reflect.DeepEqual(got, []interface{}{"male", "female"}) {
+		t.Errorf("gender enum = %v", got)
+	}
+	if got := s.Properties["level"].Enum; !reflect.DeepEqual(got, []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Errorf("level enum = %v", got)
+	}
+	if s.Properties["id_card"].Pattern != chineseIDPattern {
+		t.Errorf("id_card = %+v", s.Properties["id_card"])
+	}
+	if s.Properties["phone"].Pattern != `^1\d{10}$` {
+		t.Errorf("phone = %+v", s.Properties["phone"])
+	}
+	if !s.Properties["nickname"].Nullable {
+		t.Error("pointer field should be nullable")
+	}
+	if tags := s.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" || *tags.MaxItems != 5 {
+		t.Errorf("tags = %+v", tags)
+	}
+	if extra := s.Properties["extra"]; extra.Type != "object" || extra.AdditionalProperties.Type != "integer" {
+		t.Errorf("extra = %+v", extra)
+	}
+	if created := s.Properties["created_at"]; created.Type != "string" || created.Format != "date-time" {
+		t.Errorf("created_at = %+v", created)
+	}
+	if avatar := s.Properties["avatar"]; avatar.Format != "byte" {
+		t.Errorf("avatar = %+v", avatar)
+	}
+
+	if got := s.Properties["address"].Ref; got != "#/$defs/address" {
+		t.Errorf("address ref = %q", got)
+	}
+	if def := s.Defs["address"]; def == nil || !reflect.DeepEqual(def.Required, []string{"city"}) {
+		t.Errorf("address def = %+v", def)
+	}
+}
+
+func TestJSONSchemaRecursive(t *testing.T) {
+	s := JSONSchema(node{})
+	children := s.Properties["children"]
+	if children.Type != "array" || children.Items.Ref != "#/$defs/node" {
+		t.Errorf("children = %+v", children)
+	}
+	if _, ok := s.Defs["node"]; !ok {
+		t.Errorf("defs = %v", s.Defs)
+	}
+}
+
+func TestGeneratorGenericName(t *testing.T) {
+	g := NewGenerator("#/components/schemas/")
+	s := g.For(api.TypedResponse[[]address]{})
+	if s.Ref != "#/components/schemas/TypedResponse_List_address" {
+		t.Fatalf("ref = %q", s.Ref)
+	}
+	def := g.Definitions()["TypedResponse_List_address"]
+	if data := def.Properties["data"]; data.Type != "array" || data.Items.Ref != "#/components/schemas/address" {
+		t.Errorf("data = %+v", data)
+	}
+	if code := def.Properties["code"]; code.Type != "integer" {
+		t.Errorf("code = %+v", code)
+	}
+}
+
+func TestGeneratorIntegerFormat(t *testing.T) {
+	g := NewGenerator("#/$defs/")
+	cases := []struct {
+		v      interface{}
+		format string
+	}{
+		{int8(0), "int32"}, {int32(0), "int32"}, {uint16(0), "int32"},
+		{int(0), "int64"}, {uint(0), "int64"}, {uint32(0), "int64"}, {uint64(0), "int64"},
+	}
+	for _, c := range cases {
+		if s := g.Type(reflect.TypeOf(c.v)); s.Type != "integer" || s.Format != c.format {
+			t.Errorf("%T = %+v, want format %s", c.v, s, c.format)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	got := parseRules("required, min=1,regex=^a,b$")
+	want := []string{"required", "min=1", "regex=^a,b$"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRules = %v, want %v", got, want)
+	}
+}
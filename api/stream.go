@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/bytedance/sonic"
+)
+
+// 流式响应的编码
+const (
+	MediaTypeEventStream = "text/event-stream"    // Server-Sent Events
+	MediaTypeNDJSON      = "application/x-ndjson" // 每行一个JSON
+)
+
+// ErrStreamClosed 流已经调用过 Close
+var ErrStreamClosed = errors.New("api: stream closed")
+
+// Chunk 流式响应的单个数据块，JSON 字段与 DataResponse 一致
+// Seq 从1开始递增，最后一个数据块 Done 为 true，Code、Message 为最终结果
+type Chunk struct {
+	Message string      `json:"message"`
+	Code    int         `json:"code"`
+	Data    interface{} `json:"data,omitempty"`
+	Seq     int64       `json:"seq"`
+	Done    bool        `json:"done,omitempty"`
+}
+
+func (c *Chunk) GetCode() int {
+	return c.Code
+}
+
+func (c *Chunk) GetMessage() string {
+	return c.Message
+}
+
+func (c *Chunk) GetData() interface{} {
+	return c.Data
+}
+
+// Stream 流式响应写入器，每次 Send 后立即 flush，并发安全
+// 客户端断开后 Send 返回 context 错误，调用方应停止生成内容
+type Stream struct {
+	mu        sync.Mutex
+	w         io.Writer
+	rc        *http.ResponseController
+	ctx       context.Context
+	mediaType string
+	locale    string
+	seq       int64
+	closed    bool
+}
+
+// NewStream 根据 Accept 请求头创建流式响应，接受 text/event-stream 时使用SSE，否则使用NDJSON
+func NewStream(w http.ResponseWriter, r *http.Request) (*Stream, error) {
+	for _, accept := range parseQualityList(r.Header.Get("Accept")) {
+		if mediaType, _, _ := mime.ParseMediaType(accept); mediaType == MediaTypeEventStream {
+			return NewSSE(w, r)
+		}
+	}
+	return NewNDJSON(w, r)
+}
+
+// NewSSE 创建 Server-Sent Events 流式响应，写入响应头并立即 flush
+// 数据块格式为 id: seq、event: message（最后一个为 done）、data: JSON
+func NewSSE(w http.ResponseWriter, r *http.Request) (*Stream, error) {
+	return newStream(w, r, MediaTypeEventStream)
+}
+
+// NewNDJSON 创建 NDJSON 流式响应，写入响应头并立即 flush，每个数据块一行JSON
+func NewNDJSON(w http.ResponseWriter, r *http.Request) (*Stream, error) {
+	return newStream(w, r, MediaTypeNDJSON)
+}
+
+func newStream(w http.ResponseWriter, r *http.Request, mediaType string) (*Stream, error) {
+	writeHeader(w, r, mediaType)
+	header := w.Header()
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // 禁用 nginx 缓冲
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return nil, fmt.Errorf("api: streaming not supported: %w", err)
+	}
+	return &Stream{
+		w:         w,
+		rc:        rc,
+		ctx:       r.Context(),
+		mediaType: mediaType,
+		locale:    LocaleFromRequest(r),
+	}, nil
+}
+
+// Context 返回请求的 context，客户端断开时被取消
+func (s *Stream) Context() context.Context {
+	return s.ctx
+}
+
+// Send 发送一个成功的数据块
+func (s *Stream) Send(data interface{}) error {
+	return s.write(&Chunk{Message: "success", Data: data})
+}
+
+// SendChunk 发送自定义的数据块，Seq、Done 由 Stream 填充
+func (s *Stream) SendChunk(chunk *Chunk) error {
+	c := *chunk
+	c.Done = false
+	return s.write(&c)
+}
+
+// Close 发送最后一个数据块并结束流，err 为nil时 Code 为0，否则 Code、Message 按 FromError 规则转换
+// 客户端已断开时不再写入，返回 context 错误；重复调用返回 ErrStreamClosed
+func (s *Stream) Close(err error) error {
+	code, message := errorCodeMessage(err, s.locale)
+	return s.write(&Chunk{Message: message, Code: code, Done: true})
+}
+
+// Heartbeat 发送保活数据，SSE 为注释行，NDJSON 为空行，客户端应忽略
+// 用于生成内容较慢时避免代理超时断开
+func (s *Stream) Heartbeat() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.check(); err != nil {
+		return err
+	}
+	line := "\n"
+	if s.mediaType == MediaTypeEventStream {
+		line = ":\n\n"
+	}
+	if _, err := io.WriteString(s.w, line); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (s *Stream) check() error {
+	if s.closed {
+		return ErrStreamClosed
+	}
+	return s.ctx.Err()
+}
+
+func (s *Stream) write(chunk *Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.check(); err != nil {
+		return err
+	}
+	if chunk.Done {
+		s.closed = true
+	}
+	s.seq++
+	chunk.Seq = s.seq
+
+	body, err := sonic.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	if s.mediaType == MediaTypeEventStream {
+		event := "message"
+		if chunk.Done {
+			event = "done"
+		}
+		_, err = fmt.Fprintf(s.w, "id: %s\nevent: %s\ndata: %s\n\n", strconv.FormatInt(chunk.Seq, 10), event, body)
+	} else {
+		_, err = fmt.Fprintf(s.w, "%s\n", body)
+	}
+	if err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// StreamChannel 将 ch 中的数据逐个发送，ch 关闭后读取 errc 中的错误作为最终结果并结束流
+// errc 需要在 ch 关闭后发送一个错误或被关闭，为nil时视为成功；客户端断开时立即返回 context 错误，调用方应通过同一个 context 停止生产者
+func StreamChannel[T any](s *Stream, ch <-chan T, errc <-chan error) error {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case v, ok := <-ch:
+			if !ok {
+				var err error
+				if errc != nil {
+					select {
+					case err = <-errc:
+					case <-s.ctx.Done():
+						return s.ctx.Err()
+					}
+				}
+				return s.Close(err)
+			}
+			if err := s.Send(v); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+func TestNDJSONStream(t *testing.T) {
+	r := httptest.NewRequest("GET", "/chat", nil)
+	w := httptest.NewRecorder()
+
+	s, err := NewStream(w, r)
+	if err != nil {
+		t.Fatalf("NewStream failed: %v", err)
+	}
+	if !w.Flushed {
+		t.Error("Expected headers to be flushed")
+	}
+	s.Send("你好")
+	s.Send("世界")
+	if err := s.Close(nil); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := s.Send("late"); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Expected ErrStreamClosed, got %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+	expected := `{"message":"success","code":0,"data":"你好","seq":1}
+{"message":"success","code":0,"data":"世界","seq":2}
+{"message":"success","code":0,"seq":3,"done":true}
+`
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
+
+func TestSSEStream(t *testing.T) {
+	r := httptest.NewRequest("GET", "/chat", nil)
+	r.Header.Set("Accept", "text/event-stream")
+	r.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+
+	s, err := NewStream(w, r)
+	if err != nil {
+		t.Fatalf("NewStream failed: %v", err)
+	}
+	s.Send(map[string]string{"delta": "hi"})
+	s.Heartbeat()
+	s.Close(errUserNotFound)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream; charset=utf-8" {
+		t.Errorf("Unexpected content type: %s", ct)
+	}
+	if w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("Expected no-cache, got %v", w.Header())
+	}
+	expected := "id: 1\nevent: message\ndata: {\"message\":\"success\",\"code\":0,\"data\":{\"delta\":\"hi\"},\"seq\":1}\n\n" +
+		":\n\n" +
+		"id: 2\nevent: done\ndata: {\"message\":\"user not found\",\"code\":19404,\"seq\":2,\"done\":true}\n\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestStreamChannel(t *testing.T) {
+	r := httptest.NewRequest("GET", "/chat", nil)
+	w := httptest.NewRecorder()
+	s, _ := NewNDJSON(w, r)
+
+	ch := make(chan string)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for _, token := range []string{"a", "b"} {
+			ch <- token
+		}
+		errc <- errors.New("llm timeout")
+	}()
+	if err := StreamChannel(s, ch, errc); err != nil {
+		t.Fatalf("StreamChannel failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 chunks, got %q", w.Body.String())
+	}
+	var last Chunk
+	sonic.UnmarshalString(lines[2], &last)
+	if !last.Done || last.Code != -1 || last.Message != DefaultErrorMessage {
+		t.Errorf("Unexpected terminal chunk: %+v", last)
+	}
+}
+
+func TestStreamClientDisconnect(t *testing.T) {
+	result := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := NewSSE(w, r)
+		if err != nil {
+			result <- err
+			return
+		}
+		for {
+			if err := s.Send("tick"); err != nil {
+				result <- err
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if line != "id: 1\n" {
+		t.Errorf("Unexpected first line: %q", line)
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("Expected error after client disconnect")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Stream did not stop after client disconnect")
+	}
+}
+
+func TestStreamNotSupported(t *testing.T) {
+	r := httptest.NewRequest("GET", "/chat", nil)
+	if _, err := NewSSE(struct{ http.ResponseWriter }{httptest.NewRecorder()}, r); err == nil {
+		t.Error("Expected error for writer without Flush")
+	}
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/id"
+)
+
+var regexCache sync.Map // pattern -> *regexp.Regexp
+
+// Validate 按 validate 标签校验结构体，多条规则用逗号分隔：
+//   - required   不能为零值（字符串非空、切片非空、指针非nil）
+//   - min=N      数字不小于N，字符串字符数、切片长度不小于N
+//   - max=N      数字不大于N，字符串字符数、切片长度不大于N
+//   - enum=a|b   值必须是列出的值之一
+//   - chinese_id 中国18位身份证号（id.ValidateChinese）
+//   - regex=expr 字符串匹配正则表达式，regex 必须是最后一条规则，expr 中可以包含逗号
+//
+// 非 required 的字段为零值时跳过其他规则；嵌套的结构体、结构体切片会递归校验
+// 字段名按 path > query > form > json 的顺序取第一个标签，与 Bind 一致
+// 校验失败返回 ErrInvalidParam，可通过 FieldErrors 获取每个字段的错误
+func Validate(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("api: Validate requires a struct, got %T", v)
+	}
+
+	var errs errorutil.MultiError
+	validateStruct(rv, "", &errs)
+	if errs.Len() > 0 {
+		return ErrInvalidParam.WithCause(&errs)
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *errorutil.MultiError) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			validateStruct(fv, prefix, errs)
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		if err := validateField(fv, field.Tag.Get("validate")); err != nil {
+			errs.Add(name, err)
+			continue
+		}
+		validateNested(fv, name, errs)
+	}
+}
+
+func validateNested(fv reflect.Value, name string, errs *errorutil.MultiError) {
+	switch fv.Kind() {
+	case reflect.Pointer:
+		if !fv.IsNil() {
+			validateNested(fv.Elem(), name, errs)
+		}
+	case reflect.Struct:
+		validateStruct(fv, name+".", errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			validateNested(fv.Index(i), fmt.Sprintf("%s[%d]", name, i), errs)
+		}
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"path", "query", "form", "json"} {
+		if name := tagName(field, key); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func validateField(fv reflect.Value, tag string) error {
+	if tag == "" || tag == "-" {
+		return nil
+	}
+
+	rules := []string{}
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+
+	if fv.IsZero() {
+		for _, rule := range rules {
+			if rule == "required" {
+				return errors.New("不能为空")
+			}
+		}
+		return nil
+	}
+
+	value := reflect.Indirect(fv)
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		var err error
+		switch name {
+		case "required":
+		case "min":
+			err = checkRange(value, arg, true)
+		case "max":
+			err = checkRange(value, arg, false)
+		case "enum":
+			err = checkEnum(value, arg)
+		case "chinese_id":
+			if value.Kind() != reflect.String || !id.ValidateChinese(value.String()) {
+				err = errors.New("身份证号格式不正确")
+			}
+		case "regex":
+			err = checkRegex(value, arg)
+		default:
+			err = fmt.Errorf("未知的校验规则 %s", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkRange(value reflect.Value, arg string, isMin bool) error {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Errorf("无效的校验规则参数 %s", arg)
+	}
+
+	var n float64
+	isLength := false
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = value.Float()
+	case reflect.String:
+		n, isLength = float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, isLength = float64(value.Len()), true
+	default:
+		return fmt.Errorf("类型 %s 不支持 min/max 校验", value.Type())
+	}
+
+	switch {
+	case isMin && n < limit && isLength:
+		return fmt.Errorf("长度不能小于 %s", arg)
+	case isMin && n < limit:
+		return fmt.Errorf("不能小于 %s", arg)
+	case !isMin && n > limit && isLength:
+		return fmt.Errorf("长度不能大于 %s", arg)
+	case !isMin && n > limit:
+		return fmt.Errorf("不能大于 %s", arg)
+	}
+	return nil
+}
+
+func checkEnum(value reflect.Value, arg string) error {
+	options := strings.Split(arg, "|")
+	s := fmt.Sprint(value.Interface())
+	for _, option := range options {
+		if s == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("必须是 %s 之一", strings.Join(options, ", "))
+}
+
+func checkRegex(value reflect.Value, pattern string) error {
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("类型 %s 不支持 regex 校验", value.Type())
+	}
+	re, ok := regexCache.Load(pattern)
+	if !ok {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("无效的正则表达式 %s", pattern)
+		}
+		re, _ = regexCache.LoadOrStore(pattern, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(value.String()) {
+		return errors.New("格式不正确")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package delaytask
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Bucket 某个tick下的待处理任务队列（包含该tick下所有子队列）
+// Tick*interval 即为队列内任务的到期时间（毫秒）
+type Bucket struct {
+	Tick  int64    `json:"tick"`
+	Depth int64    `json:"depth"`
+	Tasks []string `json:"tasks,omitempty"`
+
+	lanes []bucketLane // 按拉取顺序排列：优先级从高到低
+}
+
+type bucketLane struct {
+	key      string
+	priority int
+}
+
+// ListBuckets 列出taskname下所有未消费完的队列，按tick升序
+// 使用SCAN遍历，不会阻塞Redis，但结果不是严格的快照
+func ListBuckets(r *redis.Client, ctx context.Context, taskname string) ([]Bucket, error) {
+	prefix := fmt.Sprintf("dtaskq:{%s}:", taskname)
+
+	index := map[int64]int{}
+	buckets := []Bucket{}
+	iter := r.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		// dtaskq:{taskname}:{tick} 或子队列 dtaskq:{taskname}:{tick}:{priority}:{tenant}
+		tickstr, member, isLane := strings.Cut(strings.TrimPrefix(key, prefix), ":")
+		tick, err := strconv.ParseInt(tickstr, 10, 64)
+		if err != nil {
+			continue // taskname 本身包含 ':' 时可能匹配到其他任务的key
+		}
+		depth, err := r.LLen(ctx, key).Result()
+		if err != nil {
+			return []Bucket{}, err
+		}
+		if depth == 0 {
+			continue
+		}
+
+		prio := PRIORITY_NORMAL
+		if isLane {
+			prio = parseLaneMember(member).Priority
+		}
+		i, ok := index[tick]
+		if !ok {
+			i = len(buckets)
+			index[tick] = i
+			buckets = append(buckets, Bucket{Tick: tick})
+		}
+		buckets[i].Depth += depth
+		buckets[i].lanes = append(buckets[i].lanes, bucketLane{key: key, priority: prio})
+	}
+	if err := iter.Err(); err != nil {
+		return []Bucket{}, err
+	}
+
+	for _, bucket := range buckets {
+		lanes := bucket.lanes
+		sort.Slice(lanes, func(i, j int) bool {
+			if lanes[i].priority != lanes[j].priority {
+				return lanes[i].priority > lanes[j].priority
+			}
+			return lanes[i].key < lanes[j].key
+		})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Tick < buckets[j].Tick })
+	return buckets, nil
+}
+
+// PeekTasks 查看到期时间在[start_time, end_time]（毫秒）内的任务，不会消费任务
+// limit 为每个Bucket（同一tick下所有子队列合计）最多返回的任务数，<=0 表示全部返回；子队列按优先级从高到低依次填充
+func PeekTasks(r *redis.Client, ctx context.Context, taskname string, start_time int64, end_time int64, interval int64, limit int64) ([]Bucket, error) {
+	buckets, err := ListBuckets(r, ctx, taskname)
+	if err != nil {
+		return []Bucket{}, err
+	}
+
+	result := []Bucket{}
+	for _, bucket := range buckets {
+		due := bucket.Tick * interval
+		if due < start_time || due > end_time {
+			continue
+		}
+		bucket.Tasks = []string{}
+		for _, lane := range bucket.lanes {
+			stop := int64(-1)
+			if limit > 0 {
+				if int64(len(bucket.Tasks)) >= limit {
+					break
+				}
+				stop = limit - int64(len(bucket.Tasks)) - 1
+			}
+			tasks, err := r.LRange(ctx, lane.key, 0, stop).Result()
+			if err != nil {
+				return []Bucket{}, err
+			}
+			bucket.Tasks = append(bucket.Tasks, tasks...)
+		}
+		result = append(result, bucket)
+	}
+	return result, nil
+}
+
+// PurgeTask 删除taskname下所有待处理任务以及拉取进度，返回被删除的任务数
+func PurgeTask(r *redis.Client, ctx context.Context, taskname string) (int64, error) {
+	buckets, err := ListBuckets(r, ctx, taskname)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	keys := []string{fmt.Sprintf("dtaskt:{%s}", taskname)}
+	for _, bucket := range buckets {
+		for _, lane := range bucket.lanes {
+			keys = append(keys, lane.key)
+		}
+		keys = append(keys, fmt.Sprintf("dtaskl:{%s}:%d", taskname, bucket.Tick))
+		purged += bucket.Depth
+	}
+	if err := r.Del(ctx, keys...).Err(); err != nil {
+		return 0, err
+	}
+	observeDepth(taskname, 0)
+	return purged, nil
+}
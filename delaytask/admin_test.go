@@ -0,0 +1,130 @@
+package delaytask
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	times "github.com/daozhonglee/go-util/times"
+)
+
+func TestListBuckets(t *testing.T) {
+	r := setupRedisClient()
+	ctx := context.Background()
+
+	// 测试Redis连接
+	if err := r.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	taskname := "test_list_buckets"
+	interval := int64(INTERVAL_SECONDS)
+
+	// 清理测试数据
+	defer cleanupRedisKeys(r, ctx, fmt.Sprintf("dtaskq:{%s}:*", taskname))
+
+	now := times.GetCurrentMilliUnix()
+	later := now + 10*interval
+	PushTaskInternal(r, ctx, taskname, later, "task2", interval)
+	PushTaskInternal(r, ctx, taskname, now, "task1", interval)
+	PushTaskInternal(r, ctx, taskname, later, "task3", interval)
+
+	buckets, err := ListBuckets(r, ctx, taskname)
+	if err != nil {
+		t.Fatalf("ListBuckets failed: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d: %v", len(buckets), buckets)
+	}
+	if buckets[0].Tick >= buckets[1].Tick {
+		t.Errorf("Expected buckets sorted by tick, got %v", buckets)
+	}
+	if buckets[0].Depth != 1 || buckets[1].Depth != 2 {
+		t.Errorf("Unexpected bucket depth: %v", buckets)
+	}
+}
+
+func TestPeekTasks(t *testing.T) {
+	r := setupRedisClient()
+	ctx := context.Background()
+
+	// 测试Redis连接
+	if err := r.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	taskname := "test_peek"
+	interval := int64(INTERVAL_SECONDS)
+
+	// 清理测试数据
+	defer cleanupRedisKeys(r, ctx, fmt.Sprintf("dtaskq:{%s}:*", taskname))
+
+	now := times.GetCurrentMilliUnix()
+	PushTaskInternal(r, ctx, taskname, now, "task1", interval)
+	PushTaskInternal(r, ctx, taskname, now, "task2", interval)
+	PushTaskInternal(r, ctx, taskname, now+60*interval, "task3", interval)
+
+	// 只查看当前时间附近的任务，且每个Bucket最多返回1个
+	buckets, err := PeekTasks(r, ctx, taskname, now-interval, now+interval, interval, 1)
+	if err != nil {
+		t.Fatalf("PeekTasks failed: %v", err)
+	}
+	if len(buckets) != 1 {
+		t.Fatalf("Expected 1 bucket, got %d: %v", len(buckets), buckets)
+	}
+	if len(buckets[0].Tasks) != 1 || buckets[0].Tasks[0] != "task1" {
+		t.Errorf("Expected [task1], got %v", buckets[0].Tasks)
+	}
+	if buckets[0].Depth != 2 {
+		t.Errorf("Expected depth 2, got %d", buckets[0].Depth)
+	}
+
+	// 查看不应该消费任务
+	all, err := ListBuckets(r, ctx, taskname)
+	if err != nil {
+		t.Fatalf("ListBuckets failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("Expected 2 buckets after peek, got %d", len(all))
+	}
+}
+
+func TestPurgeTask(t *testing.T) {
+	r := setupRedisClient()
+	ctx := context.Background()
+
+	// 测试Redis连接
+	if err := r.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	taskname := "test_purge"
+	interval := int64(INTERVAL_SECONDS)
+
+	// 清理测试数据
+	defer cleanupRedisKeys(r, ctx,
+		fmt.Sprintf("dtaskq:{%s}:*", taskname),
+		fmt.Sprintf("dtaskt:{%s}", taskname))
+
+	now := times.GetCurrentMilliUnix()
+	PushTaskInternal(r, ctx, taskname, now, "task1", interval)
+	PushTaskInternal(r, ctx, taskname, now+interval, "task2", interval)
+	PullTaskInternal(r, ctx, taskname, interval)
+
+	purged, err := PurgeTask(r, ctx, taskname)
+	if err != nil {
+		t.Fatalf("PurgeTask failed: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 purged tasks, got %d", purged)
+	}
+
+	buckets, _ := ListBuckets(r, ctx, taskname)
+	if len(buckets) != 0 {
+		t.Errorf("Expected no buckets after purge, got %v", buckets)
+	}
+	exists, _ := r.Exists(ctx, fmt.Sprintf("dtaskt:{%s}", taskname)).Result()
+	if exists != 0 {
+		t.Error("Expected tick key to be removed after purge")
+	}
+}
@@ -3,6 +3,7 @@ package delaytask
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	times "github.com/daozhonglee/go-util/times"
 	"github.com/redis/go-redis/v9"
@@ -21,6 +22,7 @@ func PushTaskInternal(r *redis.Client, ctx context.Context, taskname string, tic
 	args := []string{content, fmt.Sprintf("%d", 60*60*60*24)} // WARN: expire time
 
 	_, err := r.Eval(ctx, "redis.call('rpush', KEYS[1], ARGV[1]); redis.call('expire', KEYS[1], ARGV[2]); return 0", keys, args).Result()
+	observePush(taskname, err)
 	return err
 }
 
@@ -32,6 +34,7 @@ func PullTaskInternal(r *redis.Client, ctx context.Context, taskname string, int
 	args := []string{fmt.Sprintf("%d", tm), fmt.Sprintf("%d", 7200)}
 
 	if ts, err := r.Eval(ctx, "local v = redis.call('get', KEYS[1]); if (not v) then redis.call('setex', KEYS[1], ARGV[2], ARGV[1]); return ARGV[1] end return v", keys, args).Text(); err != nil {
+		observeLuaError(taskname, "tick", err)
 		return []string{}, err
 	} else {
 		keys = append(keys, fmt.Sprintf("dtaskq:{%s}:%s", taskname, ts))
@@ -50,8 +53,16 @@ func PullTaskInternal(r *redis.Client, ctx context.Context, taskname string, int
 			"end return rt;", keys, args)
 
 		if ret, err := result.StringSlice(); err != nil {
+			observeLuaError(taskname, "pull", err)
 			return []string{}, err
 		} else {
+			if metricsEnabled() {
+				tick, _ := strconv.ParseInt(ts, 10, 64)
+				observePull(taskname, tick, interval, len(ret))
+				if depth, err := r.LLen(ctx, keys[1]).Result(); err == nil {
+					observeDepth(taskname, depth)
+				}
+			}
 			return ret, nil
 		}
 	}
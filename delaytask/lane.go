@@ -0,0 +1,217 @@
+package delaytask
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/daozhonglee/go-util/random"
+	times "github.com/daozhonglee/go-util/times"
+	"github.com/redis/go-redis/v9"
+)
+
+// 优先级，数值越大越先被拉取
+const (
+	PRIORITY_LOW    = -1
+	PRIORITY_NORMAL = 0
+	PRIORITY_HIGH   = 1
+)
+
+// Lane 同一个tick下的子队列，由优先级和租户区分
+// 默认Lane（PRIORITY_NORMAL且无租户）与 PushTask 使用同一个队列
+type Lane struct {
+	Priority int    `json:"priority"`
+	Tenant   string `json:"tenant"`
+}
+
+// LaneTask 拉取到的任务及其所在的子队列
+type LaneTask struct {
+	Lane
+	Content string `json:"content"`
+}
+
+func (l Lane) isDefault() bool {
+	return l.Priority == PRIORITY_NORMAL && l.Tenant == ""
+}
+
+func (l Lane) member() string {
+	return fmt.Sprintf("%d:%s", l.Priority, l.Tenant)
+}
+
+func parseLaneMember(member string) Lane {
+	prio, tenant, _ := strings.Cut(member, ":")
+	p, _ := strconv.Atoi(prio)
+	return Lane{Priority: p, Tenant: tenant}
+}
+
+// 子队列 key: dtaskq:{taskname}:{tick}:{priority}:{tenant}
+// 子队列索引 key: dtaskl:{taskname}:{tick}，zset，score 为优先级
+func PushLaneTaskInternal(r *redis.Client, ctx context.Context, taskname string, lane Lane, tick_time int64, content string, interval int64) error {
+	if lane.isDefault() {
+		return PushTaskInternal(r, ctx, taskname, tick_time, content, interval)
+	}
+
+	next_tick := (tick_time + interval - 1) / interval
+	keys := []string{
+		fmt.Sprintf("dtaskq:{%s}:%d:%s", taskname, next_tick, lane.member()),
+		fmt.Sprintf("dtaskl:{%s}:%d", taskname, next_tick),
+	}
+	args := []string{content, fmt.Sprintf("%d", 60*60*60*24), fmt.Sprintf("%d", lane.Priority), lane.member()} // WARN: expire time
+
+	_, err := r.Eval(ctx, "redis.call('rpush', KEYS[1], ARGV[1]); redis.call('expire', KEYS[1], ARGV[2]); "+
+		"redis.call('zadd', KEYS[2], ARGV[3], ARGV[4]); redis.call('expire', KEYS[2], ARGV[2]); return 0", keys, args).Result()
+	observePush(taskname, err)
+	return err
+}
+
+// 按优先级从高到低拉取，同一优先级内按租户权重轮询，每个租户每轮最多拉取权重个任务
+// weights 为租户权重，未配置的租户权重为1；默认队列的租户为空字符串
+// KEYS[3:] 为按优先级排列的子队列，ARGV[6:6+2*ARGV[5]] 为对应的 member 和优先级，之后为租户权重
+// 读取子队列索引后新增的子队列本次不拉取，此时不推进 tick，留给下次拉取
+var pullLaneScript = "local lanecnt = tonumber(ARGV[5]) " +
+	"local weights = {} " +
+	"for i = 6 + 2 * lanecnt, #ARGV, 2 do weights[ARGV[i]] = tonumber(ARGV[i+1]) end " +
+	"local batch = tonumber(ARGV[3]); local seed = tonumber(ARGV[4]); " +
+	"local levels = {}; local known = {} " +
+	"for i = 1, lanecnt do " +
+	"    local member = ARGV[4 + 2 * i]; local prio = tonumber(ARGV[5 + 2 * i]); " +
+	"    local last = levels[#levels]; " +
+	"    if (not last) or last.prio ~= prio then last = {prio = prio, lanes = {}}; levels[#levels+1] = last end " +
+	"    local tenant = string.sub(member, string.find(member, ':', 1, true) + 1); " +
+	"    last.lanes[#last.lanes+1] = {member = member, key = KEYS[i+2], tenant = tenant, done = false, index = (member ~= '0:')}; " +
+	"    known[member] = true; " +
+	"end " +
+	"local stale = false " +
+	"for _, member in ipairs(redis.call('zrange', KEYS[2], 0, -1)) do " +
+	"    if not known[member] then stale = true; break end " +
+	"end " +
+	"local rt = {0}; local n = 0 " +
+	"for _, level in ipairs(levels) do " +
+	"    local lanes = level.lanes; local cnt = #lanes; local active = true " +
+	"    while active and n < batch do " +
+	"        active = false " +
+	"        for j = 0, cnt - 1 do " +
+	"            local lane = lanes[((j + seed) % cnt) + 1] " +
+	"            if not lane.done then " +
+	"                local w = weights[lane.tenant] or 1; if w < 1 then w = 1 end " +
+	"                for k = 1, w do " +
+	"                    if n >= batch then break end " +
+	"                    local v = redis.call('lpop', lane.key); " +
+	"                    if not v then lane.done = true; break end " +
+	"                    n = n + 1; rt[#rt+1] = lane.member; rt[#rt+1] = v; " +
+	"                end " +
+	"                if lane.done then " +
+	"                    if lane.index then redis.call('zrem', KEYS[2], lane.member) end " +
+	"                else active = true end " +
+	"            end " +
+	"            if n >= batch then break end " +
+	"        end " +
+	"    end " +
+	"end " +
+	"local remaining = 0 " +
+	"for _, level in ipairs(levels) do for _, lane in ipairs(level.lanes) do " +
+	"    if not lane.done then remaining = remaining + redis.call('llen', lane.key) end " +
+	"end end " +
+	"rt[1] = remaining " +
+	"if n == 0 and not stale then " +
+	"    local v = redis.call('get', KEYS[1]); " +
+	"    if (not v) then " +
+	"        redis.call('setex', KEYS[1], ARGV[2], ARGV[1]); return rt; " +
+	"    end " +
+	"    if tonumber(v) < tonumber(ARGV[1]) then redis.call('incr', KEYS[1]); redis.call('expire', KEYS[1], ARGV[2]) end " +
+	"end return rt;"
+
+// tickLanes 返回 tick 下按优先级从高到低排列的子队列，默认队列排在优先级0的最后
+func tickLanes(r *redis.Client, ctx context.Context, taskname string, ts string) ([]Lane, error) {
+	members, err := r.ZRevRangeWithScores(ctx, fmt.Sprintf("dtaskl:{%s}:%s", taskname, ts), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	lanes := make([]Lane, 0, len(members)+1)
+	legacy := false
+	for _, m := range members {
+		if !legacy && m.Score < PRIORITY_NORMAL {
+			lanes = append(lanes, Lane{})
+			legacy = true
+		}
+		member, _ := m.Member.(string)
+		lane := parseLaneMember(member)
+		lane.Priority = int(m.Score)
+		lanes = append(lanes, lane)
+	}
+	if !legacy {
+		lanes = append(lanes, Lane{})
+	}
+	return lanes, nil
+}
+
+// laneKey 子队列的 key，默认队列与 PushTask 使用同一个 key
+func laneKey(taskname string, ts string, lane Lane) string {
+	if lane.isDefault() {
+		return fmt.Sprintf("dtaskq:{%s}:%s", taskname, ts)
+	}
+	return fmt.Sprintf("dtaskq:{%s}:%s:%s", taskname, ts, lane.member())
+}
+
+/* Returns tasklist of all lanes, high priority first */
+func PullLaneTaskInternal(r *redis.Client, ctx context.Context, taskname string, interval int64, weights map[string]int) ([]LaneTask, error) {
+	keys := []string{fmt.Sprintf("dtaskt:{%s}", taskname)}
+
+	tm := (times.GetCurrentMilliUnix() - 1) / interval // delayed than the push task
+	args := []string{fmt.Sprintf("%d", tm), fmt.Sprintf("%d", 7200)}
+
+	if ts, err := r.Eval(ctx, "local v = redis.call('get', KEYS[1]); if (not v) then redis.call('setex', KEYS[1], ARGV[2], ARGV[1]); return ARGV[1] end return v", keys, args).Text(); err != nil {
+		observeLuaError(taskname, "tick", err)
+		return []LaneTask{}, err
+	} else {
+		// 脚本访问的子队列都通过 KEYS 传入，与索引共享 {taskname} hash tag，集群模式下位于同一 slot
+		lanes, err := tickLanes(r, ctx, taskname, ts)
+		if err != nil {
+			return []LaneTask{}, err
+		}
+		keys = append(keys, fmt.Sprintf("dtaskl:{%s}:%s", taskname, ts))
+		args = append(args, "100", strconv.Itoa(random.Int(0, 1<<20)), strconv.Itoa(len(lanes))) // 随机起始租户，避免总是同一租户先被拉取
+		for _, lane := range lanes {
+			keys = append(keys, laneKey(taskname, ts, lane))
+			args = append(args, lane.member(), strconv.Itoa(lane.Priority))
+		}
+		for tenant, weight := range weights {
+			args = append(args, tenant, strconv.Itoa(weight))
+		}
+
+		ret, err := r.Eval(ctx, pullLaneScript, keys, args).Slice()
+		if err != nil || len(ret) == 0 {
+			observeLuaError(taskname, "lane_pull", err)
+			if err == nil {
+				err = fmt.Errorf("delaytask: unexpected lane pull result")
+			}
+			return []LaneTask{}, err
+		}
+
+		tasks := make([]LaneTask, 0, (len(ret)-1)/2)
+		for i := 1; i+1 < len(ret); i += 2 {
+			member, _ := ret[i].(string)
+			content, _ := ret[i+1].(string)
+			tasks = append(tasks, LaneTask{Lane: parseLaneMember(member), Content: content})
+		}
+
+		if metricsEnabled() {
+			tick, _ := strconv.ParseInt(ts, 10, 64)
+			observePull(taskname, tick, interval, len(tasks))
+			if remaining, ok := ret[0].(int64); ok {
+				observeDepth(taskname, remaining)
+			}
+		}
+		return tasks, nil
+	}
+}
+
+func PushLaneTask(r *redis.Client, ctx context.Context, taskname string, lane Lane, tick_time int64, content string) error {
+	return PushLaneTaskInternal(r, ctx, taskname, lane, tick_time, content, INTERVAL_SECONDS)
+}
+
+/* Returns tasklist of all lanes, high priority first */
+func PullLaneTask(r *redis.Client, ctx context.Context, taskname string, weights map[string]int) ([]LaneTask, error) {
+	return PullLaneTaskInternal(r, ctx, taskname, INTERVAL_SECONDS, weights)
+}
@@ -0,0 +1,161 @@
+package delaytask
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	times "github.com/daozhonglee/go-util/times"
+)
+
+func TestPullLaneTaskPriority(t *testing.T) {
+	r := setupRedisClient()
+	ctx := context.Background()
+
+	// 测试Redis连接
+	if err := r.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	taskname := "test_lane_priority"
+
+	// 清理测试数据
+	defer cleanupRedisKeys(r, ctx,
+		fmt.Sprintf("dtaskq:{%s}:*", taskname),
+		fmt.Sprintf("dtaskl:{%s}:*", taskname),
+		fmt.Sprintf("dtaskt:{%s}", taskname))
+
+	currentPullTime := (times.GetCurrentMilliUnix() - 1) / int64(INTERVAL_SECONDS) * int64(INTERVAL_SECONDS)
+
+	// 默认队列与普通 PushTask 共用
+	PushTask(r, ctx, taskname, currentPullTime, "normal")
+	PushLaneTask(r, ctx, taskname, Lane{Priority: PRIORITY_LOW}, currentPullTime, "low")
+	PushLaneTask(r, ctx, taskname, Lane{Priority: PRIORITY_HIGH, Tenant: "a"}, currentPullTime, "high")
+
+	time.Sleep(50 * time.Millisecond)
+
+	tasks, err := PullLaneTask(r, ctx, taskname, nil)
+	if err != nil {
+		t.Fatalf("PullLaneTask failed: %v", err)
+	}
+
+	expected := []string{"high", "normal", "low"}
+	if len(tasks) != len(expected) {
+		t.Fatalf("Expected %d tasks, got %d: %v", len(expected), len(tasks), tasks)
+	}
+	for i, content := range expected {
+		if tasks[i].Content != content {
+			t.Errorf("Expected task %d to be '%s', got '%s'", i, content, tasks[i].Content)
+		}
+	}
+	if tasks[0].Tenant != "a" || tasks[0].Priority != PRIORITY_HIGH {
+		t.Errorf("Unexpected lane for high priority task: %+v", tasks[0].Lane)
+	}
+
+	// 子队列已清空，索引也应被删除
+	tick := currentPullTime / int64(INTERVAL_SECONDS)
+	if n, _ := r.ZCard(ctx, fmt.Sprintf("dtaskl:{%s}:%d", taskname, tick)).Result(); n != 0 {
+		t.Errorf("Expected lane index to be empty, got %d members", n)
+	}
+}
+
+func TestPullLaneTaskFairness(t *testing.T) {
+	r := setupRedisClient()
+	ctx := context.Background()
+
+	// 测试Redis连接
+	if err := r.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	taskname := "test_lane_fairness"
+
+	// 清理测试数据
+	defer cleanupRedisKeys(r, ctx,
+		fmt.Sprintf("dtaskq:{%s}:*", taskname),
+		fmt.Sprintf("dtaskl:{%s}:*", taskname),
+		fmt.Sprintf("dtaskt:{%s}", taskname))
+
+	currentPullTime := (times.GetCurrentMilliUnix() - 1) / int64(INTERVAL_SECONDS) * int64(INTERVAL_SECONDS)
+
+	// 租户 noisy 推送大量任务，租户 quiet 只推送少量任务
+	for i := 0; i < 150; i++ {
+		PushLaneTask(r, ctx, taskname, Lane{Tenant: "noisy"}, currentPullTime, fmt.Sprintf("noisy-%d", i))
+	}
+	for i := 0; i < 10; i++ {
+		PushLaneTask(r, ctx, taskname, Lane{Tenant: "quiet"}, currentPullTime, fmt.Sprintf("quiet-%d", i))
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	tasks, err := PullLaneTask(r, ctx, taskname, map[string]int{"noisy": 2})
+	if err != nil {
+		t.Fatalf("PullLaneTask failed: %v", err)
+	}
+	if len(tasks) != 100 {
+		t.Fatalf("Expected a full batch of 100 tasks, got %d", len(tasks))
+	}
+
+	count := map[string]int{}
+	for _, task := range tasks {
+		count[task.Tenant]++
+	}
+	if count["quiet"] != 10 {
+		t.Errorf("Expected all 10 quiet tasks in first batch, got %d", count["quiet"])
+	}
+
+	// 剩余任务在下一次拉取中返回
+	rest, err := PullLaneTask(r, ctx, taskname, nil)
+	if err != nil {
+		t.Fatalf("PullLaneTask failed: %v", err)
+	}
+	if len(rest) != 60 {
+		t.Errorf("Expected 60 remaining tasks, got %d", len(rest))
+	}
+}
+
+func TestPurgeLaneTask(t *testing.T) {
+	r := setupRedisClient()
+	ctx := context.Background()
+
+	// 测试Redis连接
+	if err := r.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	taskname := "test_lane_purge"
+
+	// 清理测试数据
+	defer cleanupRedisKeys(r, ctx,
+		fmt.Sprintf("dtaskq:{%s}:*", taskname),
+		fmt.Sprintf("dtaskl:{%s}:*", taskname),
+		fmt.Sprintf("dtaskt:{%s}", taskname))
+
+	now := times.GetCurrentMilliUnix()
+	PushTask(r, ctx, taskname, now, "normal")
+	PushLaneTask(r, ctx, taskname, Lane{Priority: PRIORITY_HIGH, Tenant: "a"}, now, "high")
+
+	buckets, err := PeekTasks(r, ctx, taskname, 0, now+int64(INTERVAL_SECONDS), INTERVAL_SECONDS, 0)
+	if err != nil {
+		t.Fatalf("PeekTasks failed: %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].Depth != 2 {
+		t.Fatalf("Expected 1 bucket with 2 tasks, got %v", buckets)
+	}
+	if buckets[0].Tasks[0] != "high" {
+		t.Errorf("Expected high priority task first, got %v", buckets[0].Tasks)
+	}
+
+	purged, err := PurgeTask(r, ctx, taskname)
+	if err != nil {
+		t.Fatalf("PurgeTask failed: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Expected 2 purged tasks, got %d", purged)
+	}
+	keys, _ := r.Keys(ctx, fmt.Sprintf("dtask*:{%s}*", taskname)).Result()
+	if len(keys) != 0 {
+		t.Errorf("Expected no keys left after purge, got %v", keys)
+	}
+}
@@ -0,0 +1,74 @@
+package delaytask
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/daozhonglee/go-util/metric"
+	times "github.com/daozhonglee/go-util/times"
+	"github.com/redis/go-redis/v9"
+)
+
+var pushedCounter *metric.CounterVec
+var pulledCounter *metric.CounterVec
+var luaErrCounter *metric.CounterVec
+var depthGauge *metric.GaugeVec
+var lagHistogram *metric.HistogramVec
+
+// InitMetric 注册延时任务相关的监控指标，未调用时不做任何统计
+// 指标注册到普罗米修斯全局registry，一个进程内只能调用一次，否则panic
+func InitMetric(module string) {
+	pushedCounter = metric.NewCounterVec(metric.NameSpaceSugo, fmt.Sprintf("%s_%s", module, "delaytask_pushed"), "delaytask pushed tasks", []string{"taskname", "result"})
+	pulledCounter = metric.NewCounterVec(metric.NameSpaceSugo, fmt.Sprintf("%s_%s", module, "delaytask_pulled"), "delaytask pulled tasks", []string{"taskname"})
+	luaErrCounter = metric.NewCounterVec(metric.NameSpaceSugo, fmt.Sprintf("%s_%s", module, "delaytask_lua_error"), "delaytask lua script errors", []string{"taskname", "script"})
+	depthGauge = metric.NewGaugeVec(metric.NameSpaceSugo, fmt.Sprintf("%s_%s", module, "delaytask_depth"), "delaytask tasks left in current bucket", []string{"taskname"})
+	lagHistogram = metric.NewHistogramVec(metric.NameSpaceSugo, fmt.Sprintf("%s_%s", module, "delaytask_lag"), "delaytask seconds between due time and pull time", []string{"taskname"})
+}
+
+func metricsEnabled() bool {
+	return pushedCounter != nil
+}
+
+func observePush(taskname string, err error) {
+	if pushedCounter == nil {
+		return
+	}
+	if err != nil {
+		pushedCounter.Inc(taskname, "fail")
+		observeLuaError(taskname, "push", err)
+	} else {
+		pushedCounter.Inc(taskname, "success")
+	}
+}
+
+// observeLuaError 只统计脚本执行返回的错误，网络错误、context 超时等不计入 lua_error
+func observeLuaError(taskname string, script string, err error) {
+	if luaErrCounter != nil && isScriptError(err) {
+		luaErrCounter.Inc(taskname, script)
+	}
+}
+
+// isScriptError err 是否为 Redis 返回的错误回复，redis.Nil 不算错误
+func isScriptError(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && !errors.Is(err, redis.Nil)
+}
+
+// observePull tick为本次拉取的桶序号，tick*interval即为桶内任务的到期时间（毫秒）
+func observePull(taskname string, tick int64, interval int64, count int) {
+	if pulledCounter == nil || count == 0 {
+		return
+	}
+	pulledCounter.Add(float64(count), taskname)
+	lag := times.GetCurrentMilliUnix() - tick*interval
+	if lag < 0 {
+		lag = 0
+	}
+	lagHistogram.Observe(float64(lag)/1000, taskname)
+}
+
+func observeDepth(taskname string, depth int64) {
+	if depthGauge != nil {
+		depthGauge.Set(float64(depth), taskname)
+	}
+}
@@ -0,0 +1,73 @@
+package delaytask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	times "github.com/daozhonglee/go-util/times"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInitMetric(t *testing.T) {
+	r := setupRedisClient()
+	ctx := context.Background()
+
+	// 测试Redis连接
+	if err := r.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	taskname := "test_metric"
+
+	// 清理测试数据
+	defer cleanupRedisKeys(r, ctx,
+		fmt.Sprintf("dtaskq:{%s}:*", taskname),
+		fmt.Sprintf("dtaskt:{%s}", taskname))
+
+	InitMetric("delaytask_test")
+	if !metricsEnabled() {
+		t.Fatal("Expected metrics to be enabled after InitMetric")
+	}
+
+	// 开启统计后推送和拉取流程不受影响
+	currentPullTime := (times.GetCurrentMilliUnix() - 1) / int64(INTERVAL_SECONDS) * int64(INTERVAL_SECONDS)
+	if err := PushTask(r, ctx, taskname, currentPullTime, "task"); err != nil {
+		t.Errorf("PushTask failed: %v", err)
+	}
+	tasks, err := PullTask(r, ctx, taskname)
+	if err != nil {
+		t.Errorf("PullTask failed: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Errorf("Expected 1 task, got %d: %v", len(tasks), tasks)
+	}
+}
+
+// replyError 模拟 Redis 返回的错误回复
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+
+func (replyError) RedisError() {}
+
+func TestIsScriptError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{replyError("ERR Error running script"), true},
+		{fmt.Errorf("push: %w", replyError("NOSCRIPT")), true},
+		{redis.Nil, false},
+		{context.DeadlineExceeded, false},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, false},
+	}
+	for _, c := range cases {
+		if got := isScriptError(c.err); got != c.expected {
+			t.Errorf("isScriptError(%v) = %v, expected %v", c.err, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package errorutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// CodeUnknown 非 *Error 类型的错误对应的业务错误码
+const CodeUnknown = -1
+
+// Error 业务错误，包含业务错误码、HTTP状态码、面向用户的提示信息以及内部错误原因
+// Message 会返回给调用方，cause 只用于日志排查，不应该暴露给用户
+type Error struct {
+	Code    int
+	Status  int // HTTP状态码，为0时通过 StatusMapper 由 Code 推导
+	Message string
+
+	cause error
+	stack stack
+}
+
+// StatusMapper 业务错误码到HTTP状态码的默认映射，可在启动时替换
+var StatusMapper = func(code int) int {
+	switch {
+	case code == 0:
+		return http.StatusOK
+	case code >= 400 && code < 600:
+		return code
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// New 创建业务错误
+func New(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf 创建业务错误，message 支持格式化
+func Newf(code int, format string, a ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// Wrap 用业务错误包装内部错误，err 为nil时返回nil
+// 返回 error 而不是 *Error，避免 err 为nil时得到非nil的 error 接口，需要 *Error 时使用 As 取出
+func Wrap(err error, code int, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: message, cause: err}
+}
+
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.cause.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 错误码相同即认为是同一个错误，便于与预定义的错误比较
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Cause 返回内部错误原因
+func (e *Error) Cause() error {
+	return e.cause
+}
+
+// HTTPStatus 返回HTTP状态码
+func (e *Error) HTTPStatus() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	return StatusMapper(e.Code)
+}
+
+// WithCause 返回携带内部错误原因的副本，预定义的错误不会被修改
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.cause = err
+	return &c
+}
+
+// WithStatus 返回指定HTTP状态码的副本
+func (e *Error) WithStatus(status int) *Error {
+	c := *e
+	c.Status = status
+	return &c
+}
+
+// WithMessage 返回替换提示信息的副本
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// WithStack 返回记录当前调用栈的副本
+func (e *Error) WithStack() *Error {
+	c := *e
+	c.stack = callers(1)
+	return &c
+}
+
+// Frames 返回 WithStack 记录的调用栈
+func (e *Error) Frames() []Frame {
+	return e.stack.frames()
+}
+
+// Stack 返回创建时记录的调用栈，未调用 WithStack 时为空字符串
+func (e *Error) Stack() string {
+	var sb strings.Builder
+	e.stack.writeTo(&sb)
+	return strings.TrimPrefix(sb.String(), "\n")
+}
+
+// Format %s、%v 只输出错误信息，%+v 额外输出错误码和错误链中的调用栈
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, e.Error())
+		if s.Flag('+') {
+			fmt.Fprintf(s, "\ncode: %d", e.Code)
+			for _, frame := range Frames(e) {
+				fmt.Fprintf(s, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+			}
+		}
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// As 从错误链中取出 *Error
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// CodeOf 返回错误链中的业务错误码，err 为nil时返回0，非业务错误返回 CodeUnknown
+func CodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	if e, ok := As(err); ok {
+		return e.Code
+	}
+	return CodeUnknown
+}
+
+// StatusOf 返回错误对应的HTTP状态码，err 为nil时返回200，非业务错误返回500
+func StatusOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if e, ok := As(err); ok {
+		return e.HTTPStatus()
+	}
+	return http.StatusInternalServerError
+}
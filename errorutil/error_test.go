@@ -0,0 +1,97 @@
+package errorutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var errNotFound = New(1404, "记录不存在").WithStatus(http.StatusNotFound)
+
+func TestNew(t *testing.T) {
+	err := New(1001, "参数错误")
+	if err.Code != 1001 {
+		t.Errorf("Expected code 1001, got %d", err.Code)
+	}
+	if err.Error() != "参数错误" {
+		t.Errorf("Expected '参数错误', got %s", err.Error())
+	}
+	if err.HTTPStatus() != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", err.HTTPStatus())
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(nil, 1001, "参数错误") != nil {
+		t.Error("Expected nil for wrapping nil error")
+	}
+	query := func() error { return Wrap(nil, 1001, "参数错误") }
+	if err := query(); err != nil {
+		t.Errorf("Expected nil error interface, got %#v", err)
+	}
+
+	cause := errors.New("sql: no rows")
+	err := Wrap(cause, 1404, "记录不存在")
+	if !errors.Is(err, cause) {
+		t.Error("Expected errors.Is to find the cause")
+	}
+	if err.Error() != "记录不存在: sql: no rows" {
+		t.Errorf("Unexpected error string: %s", err.Error())
+	}
+	if CodeOf(err) != 1404 {
+		t.Errorf("Expected code 1404, got %d", CodeOf(err))
+	}
+}
+
+func TestErrorIsAs(t *testing.T) {
+	err := fmt.Errorf("query user: %w", errNotFound.WithCause(errors.New("sql: no rows")))
+
+	// 错误码相同即匹配
+	if !errors.Is(err, errNotFound) {
+		t.Error("Expected errors.Is to match by code")
+	}
+	if errors.Is(err, New(1001, "")) {
+		t.Error("Expected errors.Is not to match a different code")
+	}
+
+	e, ok := As(err)
+	if !ok {
+		t.Fatal("Expected As to find *Error")
+	}
+	if e.HTTPStatus() != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", e.HTTPStatus())
+	}
+
+	// With* 返回副本，不修改预定义错误
+	if errNotFound.Cause() != nil {
+		t.Error("Expected predefined error to stay unchanged")
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	if CodeOf(nil) != 0 {
+		t.Errorf("Expected 0 for nil error, got %d", CodeOf(nil))
+	}
+	if CodeOf(errors.New("boom")) != CodeUnknown {
+		t.Errorf("Expected CodeUnknown for plain error, got %d", CodeOf(errors.New("boom")))
+	}
+	if CodeOf(errNotFound) != 1404 {
+		t.Errorf("Expected 1404, got %d", CodeOf(errNotFound))
+	}
+	if StatusOf(errNotFound) != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", StatusOf(errNotFound))
+	}
+}
+
+func TestErrorWithStack(t *testing.T) {
+	err := New(1001, "参数错误")
+	if err.Stack() != "" {
+		t.Error("Expected empty stack without WithStack")
+	}
+	stack := err.WithStack().Stack()
+	if !strings.Contains(stack, "TestErrorWithStack") {
+		t.Errorf("Expected stack to contain caller, got %s", stack)
+	}
+}
@@ -0,0 +1,99 @@
+package errorutil
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxRenderItems MultiError.Error 最多输出的条目数，超出部分只输出数量，避免日志过长
+var MaxRenderItems = 5
+
+// ItemError 批量操作中单个条目的错误，Key 为条目标识（下标、字段名、任务名等）
+type ItemError struct {
+	Key string
+	Err error
+}
+
+func (e *ItemError) Error() string {
+	return "[" + e.Key + "] " + e.Err.Error()
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError 收集批量操作中的多个错误，非线程安全
+// 支持 errors.Is/As 匹配任意一个条目的错误
+type MultiError struct {
+	Items []*ItemError
+}
+
+// Add 记录条目错误，err 为nil时忽略
+func (m *MultiError) Add(key string, err error) {
+	if err == nil {
+		return
+	}
+	m.Items = append(m.Items, &ItemError{Key: key, Err: err})
+}
+
+// AddIndex 以下标作为条目标识记录错误
+func (m *MultiError) AddIndex(index int, err error) {
+	m.Add(strconv.Itoa(index), err)
+}
+
+func (m *MultiError) Len() int {
+	return len(m.Items)
+}
+
+// ErrorOrNil 没有错误时返回nil，避免返回值为 (*MultiError)(nil) 的非nil error
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.Items) == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m *MultiError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d errors: ", len(m.Items))
+	for i, item := range m.Items {
+		if i >= MaxRenderItems {
+			fmt.Fprintf(&sb, "; ... and %d more", len(m.Items)-i)
+			break
+		}
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(item.Error())
+	}
+	return sb.String()
+}
+
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(m.Items))
+	for _, item := range m.Items {
+		errs = append(errs, item)
+	}
+	return errs
+}
+
+// Format %s、%v 输出紧凑格式，%+v 逐条输出全部错误及其调用栈
+func (m *MultiError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%d errors:", len(m.Items))
+			for _, item := range m.Items {
+				fmt.Fprintf(s, "\n[%s] %+v", item.Key, item.Err)
+			}
+			return
+		}
+		io.WriteString(s, m.Error())
+	case 's':
+		io.WriteString(s, m.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", m.Error())
+	}
+}
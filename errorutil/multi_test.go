@@ -0,0 +1,55 @@
+package errorutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMultiError(t *testing.T) {
+	var m MultiError
+	if m.ErrorOrNil() != nil {
+		t.Error("Expected nil for empty MultiError")
+	}
+
+	m.Add("name", nil)
+	m.AddIndex(0, errBusiness)
+	m.Add("id", New(1001, "身份证号格式错误"))
+	if m.Len() != 2 {
+		t.Fatalf("Expected 2 items, got %d", m.Len())
+	}
+
+	err := m.ErrorOrNil()
+	if err.Error() != "2 errors: [0] business error; [id] 身份证号格式错误" {
+		t.Errorf("Unexpected error string: %s", err.Error())
+	}
+
+	// errors.Is/As 匹配任意条目
+	if !errors.Is(err, errBusiness) {
+		t.Error("Expected errors.Is to match member")
+	}
+	if CodeOf(err) != 1001 {
+		t.Errorf("Expected As to find business error, got code %d", CodeOf(err))
+	}
+	var item *ItemError
+	if !errors.As(err, &item) || item.Key != "0" {
+		t.Errorf("Expected As to find first item, got %v", item)
+	}
+}
+
+func TestMultiErrorRender(t *testing.T) {
+	var m MultiError
+	for i := 0; i < MaxRenderItems+3; i++ {
+		m.AddIndex(i, errBusiness)
+	}
+	if s := m.Error(); !strings.HasSuffix(s, "; ... and 3 more") {
+		t.Errorf("Expected truncated output, got %s", s)
+	}
+
+	m = MultiError{}
+	m.Add("task", Errorf("push failed"))
+	if s := fmt.Sprintf("%+v", &m); !strings.Contains(s, "[task] push failed\n") || !strings.Contains(s, "TestMultiErrorRender") {
+		t.Errorf("Expected '%%+v' to print every item with stack, got %q", s)
+	}
+}
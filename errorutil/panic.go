@@ -3,7 +3,6 @@ package errorutil
 
 import (
 	"fmt"
-	"runtime/debug"
 )
 
 // PanicIf 如果错误不为nil则panic
@@ -13,22 +12,27 @@ func PanicIf(err error) {
 	}
 }
 
-// PanicWithStack 如果错误不为nil则panic，并包含堆栈信息
+// PanicWithStack 如果错误不为nil则panic，panic的值为携带调用栈的错误，可通过 Frames 获取调用栈
 func PanicIfWithStack(err error) {
-	if err != nil {
-		panic(fmt.Sprintf("error: %v\nstack: %v", err, string(debug.Stack())))
+	if err != nil && !hasStack(err) {
+		err = &stackError{err: err, stack: callers(1)}
 	}
+	PanicIf(err)
 }
 
 func PanicIfWithStackAndMsg(err error, msg string) {
 	if err != nil {
-		panic(fmt.Sprintf("%s: %v\nstack: %v", msg, err, string(debug.Stack())))
+		w := &stackError{err: err, msg: msg}
+		if !hasStack(err) {
+			w.stack = callers(1)
+		}
+		panic(w)
 	}
 }
 
-// Recover 通用panic恢复函数
+// Recover 通用panic恢复函数，携带调用栈的错误会同时输出调用栈
 func Recover() {
 	if err := recover(); err != nil {
-		fmt.Printf("recover panic, err = %v\n", err)
+		fmt.Printf("recover panic, err = %+v\n", err)
 	}
 }
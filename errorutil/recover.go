@@ -0,0 +1,65 @@
+package errorutil
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// RecoverPolicy 决定 Try、RecoverTo 捕获到的panic是否转换为错误，返回false时继续panic
+// 默认 RecoverAll，可在启动时替换为 RecoverBusiness 等策略
+var RecoverPolicy = RecoverAll
+
+// RecoverAll 恢复所有panic
+func RecoverAll(r interface{}) bool {
+	return true
+}
+
+// RecoverBusiness 只恢复业务panic，runtime.Error（空指针、数组越界、类型断言失败等）继续panic
+func RecoverBusiness(r interface{}) bool {
+	_, ok := r.(runtime.Error)
+	return !ok
+}
+
+// Try 执行fn，fn中的panic会被转换为携带调用栈的错误返回
+func Try(fn func() error) (err error) {
+	defer RecoverTo(&err)
+	return fn()
+}
+
+// RecoverTo 将panic转换为携带调用栈的错误并写入errp，需要直接defer调用：
+//
+//	func handle() (err error) {
+//		defer errorutil.RecoverTo(&err)
+//		...
+//	}
+func RecoverTo(errp *error) {
+	if r := recover(); r != nil {
+		if !RecoverPolicy(r) {
+			panic(r)
+		}
+		*errp = panicToError(r)
+	}
+}
+
+// panicToError 调用栈从panic发生处开始记录；panic的值已经携带调用栈时沿用原调用栈
+func panicToError(r interface{}) error {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+	w := &stackError{err: err, msg: "panic"}
+	if !hasStack(err) {
+		w.stack = panicStack(callers(1))
+	}
+	return w
+}
+
+// panicStack 去掉 runtime.gopanic 及之前的recover相关调用
+func panicStack(s stack) stack {
+	for i, pc := range s {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			return s[i+1:]
+		}
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package errorutil
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errBusiness = errors.New("business error")
+
+func TestTry(t *testing.T) {
+	if err := Try(func() error { return nil }); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+	if err := Try(func() error { return errBusiness }); err != errBusiness {
+		t.Errorf("Expected returned error as is, got %v", err)
+	}
+
+	err := Try(func() error { panic("boom") })
+	if err == nil || err.Error() != "panic: boom" {
+		t.Fatalf("Expected 'panic: boom', got %v", err)
+	}
+	frames := Frames(err)
+	if len(frames) == 0 || !strings.Contains(frames[0].Function, "TestTry") {
+		t.Errorf("Expected stack to start at panic site, got %v", frames)
+	}
+}
+
+func TestTryPanicError(t *testing.T) {
+	err := Try(func() error { panic(errBusiness) })
+	if !errors.Is(err, errBusiness) {
+		t.Errorf("Expected errors.Is to find panic value, got %v", err)
+	}
+
+	// panic值已经携带调用栈时沿用原调用栈
+	err = Try(func() error {
+		PanicIfWithStack(errBusiness)
+		return nil
+	})
+	frames := Frames(err)
+	if len(frames) == 0 || !strings.Contains(frames[0].Function, "TestTryPanicError") {
+		t.Errorf("Expected original stack, got %v", frames)
+	}
+}
+
+func TestRecoverTo(t *testing.T) {
+	handle := func() (err error) {
+		defer RecoverTo(&err)
+		var m map[string]int
+		m["key"] = 1
+		return nil
+	}
+
+	if err := handle(); err == nil || !strings.HasPrefix(err.Error(), "panic: assignment to entry in nil map") {
+		t.Errorf("Expected panic converted to error, got %v", err)
+	}
+}
+
+func TestRecoverBusiness(t *testing.T) {
+	RecoverPolicy = RecoverBusiness
+	defer func() { RecoverPolicy = RecoverAll }()
+
+	// 业务panic被恢复
+	if err := Try(func() error { panic(errBusiness) }); !errors.Is(err, errBusiness) {
+		t.Errorf("Expected business panic to be recovered, got %v", err)
+	}
+
+	// 运行时错误继续panic
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected runtime error to panic again")
+		}
+	}()
+	Try(func() error {
+		var p *Error
+		return errors.New(p.Message)
+	})
+}
@@ -0,0 +1,155 @@
+package errorutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 内置支持的语言
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEn   = "en"
+)
+
+// DefaultLocale 注册时的默认提示信息所属的语言
+var DefaultLocale = LocaleZhCN
+
+type registered struct {
+	err      *Error
+	messages map[string]string // locale -> message
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[int]*registered{}
+	locales    = map[string]struct{}{}
+)
+
+// Register 注册错误码，message 为默认语言(DefaultLocale)的提示信息，translations 为其他语言的提示信息
+// 同一个错误码只能注册一次，重复注册返回错误
+func Register(code int, message string, translations map[string]string) (*Error, error) {
+	return RegisterWithStatus(code, 0, message, translations)
+}
+
+// RegisterWithStatus 注册错误码并指定HTTP状态码，status 为0时由 StatusMapper 推导
+func RegisterWithStatus(code int, status int, message string, translations map[string]string) (*Error, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if r, ok := registry[code]; ok {
+		return nil, fmt.Errorf("errorutil: code %d already registered with message %q", code, r.err.Message)
+	}
+
+	messages := map[string]string{DefaultLocale: message}
+	for locale, msg := range translations {
+		messages[locale] = msg
+	}
+	for locale := range messages {
+		locales[locale] = struct{}{}
+	}
+
+	err := &Error{Code: code, Status: status, Message: message}
+	registry[code] = &registered{err: err, messages: messages}
+	return err, nil
+}
+
+// MustRegister 注册错误码，重复注册直接panic，便于在启动阶段发现冲突
+// 一般用于包级变量：var ErrNotFound = errorutil.MustRegister(1404, "记录不存在", map[string]string{errorutil.LocaleEn: "record not found"})
+func MustRegister(code int, message string, translations map[string]string) *Error {
+	err, e := Register(code, message, translations)
+	if e != nil {
+		panic(e)
+	}
+	return err
+}
+
+// MustRegisterWithStatus 注册错误码并指定HTTP状态码，重复注册直接panic
+func MustRegisterWithStatus(code int, status int, message string, translations map[string]string) *Error {
+	err, e := RegisterWithStatus(code, status, message, translations)
+	if e != nil {
+		panic(e)
+	}
+	return err
+}
+
+// Lookup 查找已注册的错误码
+func Lookup(code int) (*Error, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	r, ok := registry[code]
+	if !ok {
+		return nil, false
+	}
+	return r.err, true
+}
+
+// Locales 返回所有已注册的语言，按字典序排列
+func Locales() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	result := make([]string, 0, len(locales))
+	for locale := range locales {
+		result = append(result, locale)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// MatchLocale 返回 locale 对应的已注册语言，支持只按语言匹配（如 en-US 匹配 en，zh 匹配 zh-CN）
+// 没有匹配时返回空字符串
+func MatchLocale(locale string) string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return matchLocale(locale)
+}
+
+func matchLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if locale == "" {
+		return ""
+	}
+	if _, ok := locales[locale]; ok {
+		return locale
+	}
+
+	lang, _, _ := strings.Cut(locale, "-")
+	var matched string
+	for l := range locales {
+		if strings.EqualFold(l, locale) {
+			return l
+		}
+		if base, _, _ := strings.Cut(l, "-"); strings.EqualFold(base, lang) && (matched == "" || l < matched) {
+			matched = l
+		}
+	}
+	return matched
+}
+
+// Message 返回错误码在指定语言下的提示信息，未注册的错误码返回空字符串
+func Message(code int, locale string) string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	r, ok := registry[code]
+	if !ok {
+		return ""
+	}
+	if msg, ok := r.messages[matchLocale(locale)]; ok {
+		return msg
+	}
+	return r.err.Message
+}
+
+// Localize 返回指定语言的提示信息
+// 只有错误码已注册且提示信息未被 WithMessage 修改时才翻译，否则原样返回
+func (e *Error) Localize(locale string) string {
+	if r, ok := Lookup(e.Code); ok && r.Message == e.Message {
+		return Message(e.Code, locale)
+	}
+	return e.Message
+}
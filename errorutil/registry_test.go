@@ -0,0 +1,74 @@
+package errorutil
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	err := MustRegister(9001, "余额不足", map[string]string{LocaleEn: "insufficient balance"})
+	if err.Code != 9001 || err.Message != "余额不足" {
+		t.Errorf("Unexpected registered error: %+v", err)
+	}
+
+	found, ok := Lookup(9001)
+	if !ok || !errors.Is(found, err) {
+		t.Error("Expected Lookup to find registered code")
+	}
+
+	// 重复注册返回错误
+	if _, e := Register(9001, "重复", nil); e == nil {
+		t.Error("Expected error for duplicate registration")
+	}
+}
+
+func TestMustRegisterDuplicate(t *testing.T) {
+	MustRegister(9002, "库存不足", nil)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for duplicate registration")
+		}
+	}()
+	MustRegister(9002, "库存不足", nil)
+}
+
+func TestMessage(t *testing.T) {
+	err := MustRegister(9003, "订单不存在", map[string]string{LocaleEn: "order not found"})
+
+	cases := map[string]string{
+		LocaleZhCN: "订单不存在",
+		"zh":       "订单不存在",
+		LocaleEn:   "order not found",
+		"en-US":    "order not found",
+		"fr":       "订单不存在", // 未支持的语言使用默认提示
+	}
+	for locale, expected := range cases {
+		if msg := Message(9003, locale); msg != expected {
+			t.Errorf("Expected %q for locale %s, got %q", expected, locale, msg)
+		}
+	}
+
+	if Message(9999, LocaleEn) != "" {
+		t.Error("Expected empty message for unregistered code")
+	}
+
+	// 修改过提示信息的错误不翻译
+	if msg := err.WithMessage("订单 123 不存在").Localize(LocaleEn); msg != "订单 123 不存在" {
+		t.Errorf("Expected custom message to be kept, got %q", msg)
+	}
+	if msg := err.WithCause(errors.New("db")).Localize(LocaleEn); msg != "order not found" {
+		t.Errorf("Expected translated message, got %q", msg)
+	}
+}
+
+func TestRegisterWithStatus(t *testing.T) {
+	err := MustRegisterWithStatus(9004, http.StatusNotFound, "商品不存在", nil)
+	if err.HTTPStatus() != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", err.HTTPStatus())
+	}
+	if found, _ := Lookup(9004); found.HTTPStatus() != http.StatusNotFound {
+		t.Errorf("Expected registered status 404, got %d", found.HTTPStatus())
+	}
+}
@@ -0,0 +1,147 @@
+package errorutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+)
+
+// Frame 调用栈中的一帧
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (f Frame) String() string {
+	return f.Function + " " + f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// stack 创建错误时记录的程序计数器，需要时才解析为 Frame，避免创建错误时的开销
+type stack []uintptr
+
+const maxStackDepth = 32
+
+// callers skip=0 表示调用 callers 的函数
+func callers(skip int) stack {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
+func (s stack) frames() []Frame {
+	if len(s) == 0 {
+		return nil
+	}
+	result := make([]Frame, 0, len(s))
+	frames := runtime.CallersFrames(s)
+	for {
+		frame, more := frames.Next()
+		result = append(result, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+func (s stack) writeTo(w io.Writer) {
+	for _, frame := range s.frames() {
+		fmt.Fprintf(w, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
+
+// stackError 携带调用栈的错误
+type stackError struct {
+	err   error
+	msg   string
+	stack stack
+}
+
+func (w *stackError) Error() string {
+	if w.msg == "" {
+		return w.err.Error()
+	}
+	return w.msg + ": " + w.err.Error()
+}
+
+func (w *stackError) Unwrap() error {
+	return w.err
+}
+
+func (w *stackError) Frames() []Frame {
+	return w.stack.frames()
+}
+
+// Format %s、%v 只输出错误信息，%+v 额外输出调用栈
+func (w *stackError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, w.Error())
+		if s.Flag('+') {
+			w.stack.writeTo(s)
+		}
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// WithStack 为错误记录当前调用栈，err 为nil时返回nil，错误链中已经有调用栈时原样返回
+func WithStack(err error) error {
+	if err == nil || hasStack(err) {
+		return err
+	}
+	return &stackError{err: err, stack: callers(1)}
+}
+
+// Wrapf 为错误添加上下文信息，错误链中没有调用栈时同时记录调用栈，err 为nil时返回nil
+func Wrapf(err error, format string, a ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	w := &stackError{err: err, msg: fmt.Sprintf(format, a...)}
+	if !hasStack(err) {
+		w.stack = callers(1)
+	}
+	return w
+}
+
+// Errorf 创建携带调用栈的错误，支持 %w 包装其他错误
+func Errorf(format string, a ...interface{}) error {
+	return &stackError{err: fmt.Errorf(format, a...), stack: callers(1)}
+}
+
+// Frames 返回错误链中最早记录的调用栈（离错误发生处最近），没有时返回nil
+func Frames(err error) []Frame {
+	var frames []Frame
+	for err != nil {
+		if f, ok := err.(interface{ Frames() []Frame }); ok {
+			if ff := f.Frames(); len(ff) > 0 {
+				frames = ff
+			}
+		}
+		err = errors.Unwrap(err)
+	}
+	return frames
+}
+
+func hasStack(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case *stackError:
+			if len(e.stack) > 0 {
+				return true
+			}
+		case *Error:
+			if len(e.stack) > 0 {
+				return true
+			}
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
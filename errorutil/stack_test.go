@@ -0,0 +1,78 @@
+package errorutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWithStack(t *testing.T) {
+	if WithStack(nil) != nil {
+		t.Error("Expected nil for nil error")
+	}
+
+	cause := errors.New("boom")
+	err := WithStack(cause)
+	if !errors.Is(err, cause) {
+		t.Error("Expected errors.Is to find the cause")
+	}
+	if err.Error() != "boom" {
+		t.Errorf("Expected 'boom', got %s", err.Error())
+	}
+
+	frames := Frames(err)
+	if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, "TestWithStack") {
+		t.Errorf("Expected first frame to be the caller, got %v", frames)
+	}
+
+	// 已经有调用栈时不重复记录
+	if WithStack(err) != err {
+		t.Error("Expected error with stack to be returned as is")
+	}
+}
+
+func TestWrapf(t *testing.T) {
+	err := Wrapf(Errorf("dial %s", "127.0.0.1"), "load user %d", 42)
+	if err.Error() != "load user 42: dial 127.0.0.1" {
+		t.Errorf("Unexpected error string: %s", err.Error())
+	}
+	if Wrapf(nil, "load user") != nil {
+		t.Error("Expected nil for nil error")
+	}
+}
+
+func TestFormatStack(t *testing.T) {
+	err := Errorf("boom")
+
+	if s := fmt.Sprintf("%v", err); s != "boom" {
+		t.Errorf("Expected '%%v' to print message only, got %q", s)
+	}
+
+	s := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(s, "boom\n") || !strings.Contains(s, "TestFormatStack") || !strings.Contains(s, "stack_test.go:") {
+		t.Errorf("Expected '%%+v' to print frames, got %q", s)
+	}
+
+	// 业务错误的 %+v 输出错误码以及错误链中的调用栈
+	s = fmt.Sprintf("%+v", Wrap(err, 1001, "操作失败"))
+	if !strings.Contains(s, "code: 1001") || !strings.Contains(s, "TestFormatStack") {
+		t.Errorf("Expected '%%+v' to print code and frames, got %q", s)
+	}
+}
+
+func TestPanicIfWithStackFrames(t *testing.T) {
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok {
+			t.Fatalf("Expected panic value to be error, got %T", r)
+		}
+		frames := Frames(err)
+		if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, "TestPanicIfWithStackFrames") {
+			t.Errorf("Expected first frame to be the caller, got %v", frames)
+		}
+	}()
+
+	PanicIfWithStack(errors.New("test error"))
+}
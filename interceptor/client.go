@@ -0,0 +1,79 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/daozhonglee/go-util/metric"
+	"google.golang.org/grpc"
+)
+
+// ClientActionPrefix 客户端耗时监控的 action 前缀，与服务端的耗时区分
+var ClientActionPrefix = "client:"
+
+// UnaryClient 客户端一元拦截器
+//   - 通过 metric.BeginTimerGRPC 记录耗时，action 为 ClientActionPrefix 加完整方法名
+//   - 将 context 中的链路ID写入 metadata
+//   - 返回的 gRPC 状态错误通过 FromError 转换回业务错误，可直接使用 errorutil.CodeOf、errors.Is
+//
+// grpc.NewClient(target, grpc.WithChainUnaryInterceptor(interceptor.UnaryClient), grpc.WithChainStreamInterceptor(interceptor.StreamClient))
+func UnaryClient(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	tf := metric.BeginTimerGRPC()
+	err := FromError(invoker(traceOutgoing(ctx), method, req, reply, cc, opts...))
+	metric.EndTimer(tf, ClientActionPrefix+method, err)
+	return err
+}
+
+// StreamClient 客户端流拦截器，耗时从建立流开始到接收结束（io.EOF 或错误）为止
+// 服务端只返回一条消息的流（客户端流）在成功接收该消息后结束
+func StreamClient(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	tf := metric.BeginTimerGRPC()
+	cs, err := streamer(traceOutgoing(ctx), desc, cc, method, opts...)
+	if err != nil {
+		err = FromError(err)
+		metric.EndTimer(tf, ClientActionPrefix+method, err)
+		return nil, err
+	}
+	return &clientStream{ClientStream: cs, desc: desc, done: func(err error) {
+		metric.EndTimer(tf, ClientActionPrefix+method, err)
+	}}, nil
+}
+
+// clientStream 接收结束时记录耗时，并转换错误
+type clientStream struct {
+	grpc.ClientStream
+	desc *grpc.StreamDesc
+	once sync.Once
+	done func(err error)
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = FromError(err)
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		if !s.desc.ServerStreams {
+			s.finish(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	default:
+		err = FromError(err)
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *clientStream) finish(err error) {
+	s.once.Do(func() { s.done(err) })
+}
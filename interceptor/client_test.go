@@ -0,0 +1,87 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestUnaryClientTrace(t *testing.T) {
+	conn := startTestServer(t)
+
+	ctx := context.WithValue(context.Background(), log.TRACEID, "trace-from-ctx")
+	reply := new(wrapperspb.StringValue)
+	if err := conn.Invoke(ctx, "/test.Test/Echo", wrapperspb.String("hi"), reply); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if reply.GetValue() != "trace-from-ctx" {
+		t.Errorf("Expected trace id to be propagated, got %q", reply.GetValue())
+	}
+}
+
+func TestUnaryClientError(t *testing.T) {
+	conn := startTestServer(t)
+
+	err := conn.Invoke(context.Background(), "/test.Test/Echo", wrapperspb.String("notfound"), new(wrapperspb.StringValue))
+	if !errors.Is(err, errOrderNotFound) || errorutil.CodeOf(err) != 29404 {
+		t.Errorf("Expected business error 29404, got %v", err)
+	}
+}
+
+func TestTraceOutgoing(t *testing.T) {
+	// 已有链路ID时不覆盖
+	ctx := context.WithValue(context.Background(), log.TRACEID, "b")
+	ctx = metadata.AppendToOutgoingContext(ctx, TraceIDKey, "a")
+	md, _ := metadata.FromOutgoingContext(traceOutgoing(ctx))
+	if values := md.Get(TraceIDKey); len(values) != 1 || values[0] != "a" {
+		t.Errorf("Unexpected metadata: %v", md)
+	}
+
+	if traceOutgoing(context.Background()) != context.Background() {
+		t.Error("Expected context without trace id to be unchanged")
+	}
+	var _ grpc.UnaryClientInterceptor = UnaryClient
+	var _ grpc.StreamClientInterceptor = StreamClient
+}
+
+type recvStream struct {
+	grpc.ClientStream
+	recv int
+}
+
+func (s *recvStream) RecvMsg(m interface{}) error {
+	s.recv++
+	return nil
+}
+
+func TestClientStreamFinish(t *testing.T) {
+	// 客户端流：CloseAndRecv 只接收一次，成功后即结束
+	done := 0
+	cs := &clientStream{ClientStream: &recvStream{}, desc: &grpc.StreamDesc{ClientStreams: true}, done: func(err error) {
+		if err != nil {
+			t.Errorf("Expected success, got %v", err)
+		}
+		done++
+	}}
+	if err := cs.RecvMsg(new(wrapperspb.Int64Value)); err != nil {
+		t.Fatalf("RecvMsg failed: %v", err)
+	}
+	if done != 1 {
+		t.Errorf("Expected client-streaming call to finish after one message, finished %d times", done)
+	}
+
+	// 服务端流：收到消息不结束，等待 io.EOF
+	done = 0
+	ss := &clientStream{ClientStream: &recvStream{}, desc: &grpc.StreamDesc{ServerStreams: true}, done: func(error) { done++ }}
+	ss.RecvMsg(new(wrapperspb.Int64Value))
+	ss.RecvMsg(new(wrapperspb.Int64Value))
+	if done != 0 {
+		t.Errorf("Expected server-streaming call to stay open, finished %d times", done)
+	}
+}
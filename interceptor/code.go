@@ -0,0 +1,118 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/daozhonglee/go-util/api"
+	"github.com/daozhonglee/go-util/errorutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain 业务错误在 gRPC 状态详情 ErrorInfo 中的 domain
+var ErrorDomain = "go-util"
+
+// 业务错误码在 ErrorInfo 中的 reason 和 metadata key
+const (
+	businessReason = "BUSINESS_ERROR"
+	codeMetadata   = "code"
+)
+
+// CodeMapper HTTP状态码到 gRPC 状态码的映射，业务错误先由 errorutil.StatusOf 推导HTTP状态码，可在启动时替换
+var CodeMapper = func(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if httpStatus >= 400 && httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// ToStatus 将错误转换为 gRPC 状态
+//   - 已经是 gRPC 状态错误的原样返回
+//   - context 取消、超时转换为 Canceled、DeadlineExceeded
+//   - 业务错误(*errorutil.Error)通过 CodeMapper 转换状态码，提示信息作为 message，业务错误码记录在 ErrorInfo 详情中
+//   - 其他错误转换为 Internal 和 api.DefaultErrorMessage，避免内部错误信息泄露
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+	if e, ok := errorutil.As(err); ok {
+		s := status.New(CodeMapper(e.HTTPStatus()), e.Message)
+		if d, err := s.WithDetails(&errdetails.ErrorInfo{
+			Reason:   businessReason,
+			Domain:   ErrorDomain,
+			Metadata: map[string]string{codeMetadata: strconv.Itoa(e.Code)},
+		}); err == nil {
+			return d
+		}
+		return s
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	}
+	return status.New(codes.Internal, api.DefaultErrorMessage)
+}
+
+// ToError 将错误转换为 gRPC 状态错误，err 为nil时返回nil
+func ToError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ToStatus(err).Err()
+}
+
+// FromError 将 gRPC 状态错误转换回业务错误，ErrorInfo 中没有业务错误码时原样返回
+// 返回的业务错误携带原状态错误作为 cause，status.FromError 仍然可用
+func FromError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() == codes.OK {
+		return err
+	}
+	for _, detail := range s.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != businessReason {
+			continue
+		}
+		code, e := strconv.Atoi(info.GetMetadata()[codeMetadata])
+		if e != nil {
+			continue
+		}
+		return errorutil.New(code, s.Message()).WithCause(err)
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/daozhonglee/go-util/api"
+	"github.com/daozhonglee/go-util/errorutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errOrderNotFound = errorutil.MustRegisterWithStatus(29404, http.StatusNotFound, "订单不存在", nil)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{"nil", nil, codes.OK, ""},
+		{"registered", fmt.Errorf("query: %w", errOrderNotFound), codes.NotFound, "订单不存在"},
+		{"business", errorutil.New(400, "参数错误"), codes.InvalidArgument, "参数错误"},
+		{"unknown business", errorutil.New(1001, "余额不足"), codes.Internal, "余额不足"},
+		{"status", status.Error(codes.Unavailable, "down"), codes.Unavailable, "down"},
+		{"canceled", fmt.Errorf("wait: %w", context.Canceled), codes.Canceled, "wait: context canceled"},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded, "context deadline exceeded"},
+		{"internal", errors.New("dial tcp: refused"), codes.Internal, api.DefaultErrorMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ToStatus(tt.err)
+			if s.Code() != tt.code || s.Message() != tt.message {
+				t.Errorf("Expected %v %q, got %v %q", tt.code, tt.message, s.Code(), s.Message())
+			}
+		})
+	}
+}
+
+func TestFromError(t *testing.T) {
+	err := FromError(ToError(errOrderNotFound.WithCause(errors.New("sql: no rows"))))
+	if !errors.Is(err, errOrderNotFound) || errorutil.CodeOf(err) != 29404 {
+		t.Errorf("Expected business error 29404, got %v", err)
+	}
+	if e, _ := errorutil.As(err); e.Message != "订单不存在" {
+		t.Errorf("Unexpected message: %q", e.Message)
+	}
+	if s, ok := status.FromError(err); !ok || s.Code() != codes.NotFound {
+		t.Errorf("Expected status to be kept in chain, got %v", s)
+	}
+
+	plain := status.Error(codes.Unavailable, "down")
+	if FromError(plain) != plain {
+		t.Error("Expected status error without business code to be returned as-is")
+	}
+	if FromError(nil) != nil {
+		t.Error("Expected nil")
+	}
+}
@@ -0,0 +1,77 @@
+// Package interceptor 提供 gRPC 服务端、客户端拦截器：耗时监控、链路ID传递、panic恢复、业务错误码转换
+package interceptor
+
+import (
+	"context"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/log"
+	"github.com/daozhonglee/go-util/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServer 服务端一元拦截器
+//   - 通过 metric.BeginTimerGRPC 记录耗时，action 为完整方法名，需要先调用 metric.Init
+//   - 从 metadata 获取或生成链路ID写入 log.TRACEID，并通过响应头返回
+//   - handler 中的panic转换为 Internal 错误并记录带调用栈的日志
+//   - 返回的错误通过 ToError 转换为 gRPC 状态错误
+//
+// grpc.NewServer(grpc.ChainUnaryInterceptor(interceptor.UnaryServer), grpc.ChainStreamInterceptor(interceptor.StreamServer))
+func UnaryServer(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	tf := metric.BeginTimerGRPC()
+	ctx, traceID := traceIncoming(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(TraceIDKey, traceID))
+
+	err = errorutil.Try(func() error {
+		var e error
+		resp, e = handler(ctx, req)
+		return e
+	})
+	err = serverError(ctx, info.FullMethod, err)
+	metric.EndTimer(tf, info.FullMethod, err)
+	return resp, err
+}
+
+// StreamServer 服务端流拦截器，功能与 UnaryServer 一致，耗时为整个流的处理时间
+func StreamServer(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	tf := metric.BeginTimerGRPC()
+	ctx, traceID := traceIncoming(ss.Context())
+	ss.SetHeader(metadata.Pairs(TraceIDKey, traceID))
+
+	err := errorutil.Try(func() error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	})
+	err = serverError(ctx, info.FullMethod, err)
+	metric.EndTimer(tf, info.FullMethod, err)
+	return err
+}
+
+// serverError 记录非业务错误的日志并转换为 gRPC 状态错误
+func serverError(ctx context.Context, method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := errorutil.As(err); !ok {
+		if _, ok := status.FromError(err); !ok {
+			log.ErrorStackx(ctx, err, "[GRPC] %s failed", method)
+		}
+	}
+	s := ToStatus(err)
+	if s.Code() == codes.OK {
+		return nil
+	}
+	return s.Err()
+}
+
+// serverStream 替换流的 context，使 handler 能获取链路ID
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
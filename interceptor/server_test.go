@@ -0,0 +1,220 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/daozhonglee/go-util/log"
+	"github.com/daozhonglee/go-util/metric"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testServer 手写的 ServiceDesc，避免依赖 protoc 生成代码
+// Echo 返回请求中的链路ID，请求为 panic、notfound、internal 时分别触发对应错误
+// Count 按请求数量返回递增的数字流
+type testServer struct{}
+
+func (testServer) Echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	switch in.GetValue() {
+	case "panic":
+		panic("boom")
+	case "notfound":
+		return nil, errOrderNotFound
+	case "internal":
+		return nil, errors.New("dial tcp: refused")
+	}
+	traceID, _ := ctx.Value(log.TRACEID).(string)
+	return wrapperspb.String(traceID), nil
+}
+
+func (testServer) Count(in *wrapperspb.Int64Value, stream grpc.ServerStream) error {
+	if in.GetValue() < 0 {
+		return errOrderNotFound
+	}
+	for i := int64(1); i <= in.GetValue(); i++ {
+		if err := stream.SendMsg(wrapperspb.Int64(i)); err != nil {
+			return err
+		}
+	}
+	if _, ok := stream.Context().Value(log.TRACEID).(string); !ok {
+		return errors.New("missing trace id")
+	}
+	return nil
+}
+
+var testServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Test",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.(testServer).Echo(ctx, req.(*wrapperspb.StringValue))
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Test/Echo"}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Count",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			in := new(wrapperspb.Int64Value)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			return srv.(testServer).Count(in, stream)
+		},
+	}},
+}
+
+// startTestServer 启动使用拦截器的服务端，返回使用拦截器的客户端连接
+func startTestServer(t *testing.T) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(UnaryServer), grpc.ChainStreamInterceptor(StreamServer))
+	server.RegisterService(&testServiceDesc, testServer{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClient),
+		grpc.WithChainStreamInterceptor(StreamClient),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestUnaryServerTrace(t *testing.T) {
+	conn := startTestServer(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), TraceIDKey, "trace-1")
+	var header metadata.MD
+	reply := new(wrapperspb.StringValue)
+	if err := conn.Invoke(ctx, "/test.Test/Echo", wrapperspb.String("hi"), reply, grpc.Header(&header)); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if reply.GetValue() != "trace-1" {
+		t.Errorf("Expected trace-1, got %q", reply.GetValue())
+	}
+	if values := header.Get(TraceIDKey); len(values) != 1 || values[0] != "trace-1" {
+		t.Errorf("Expected trace id header, got %v", header)
+	}
+
+	// 没有链路ID时生成
+	if err := conn.Invoke(context.Background(), "/test.Test/Echo", wrapperspb.String("hi"), reply); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if len(reply.GetValue()) != 32 {
+		t.Errorf("Expected generated trace id, got %q", reply.GetValue())
+	}
+}
+
+func TestUnaryServerErrors(t *testing.T) {
+	conn := startTestServer(t)
+
+	tests := []struct {
+		request string
+		code    codes.Code
+	}{
+		{"panic", codes.Internal},
+		{"notfound", codes.NotFound},
+		{"internal", codes.Internal},
+	}
+	for _, tt := range tests {
+		err := conn.Invoke(context.Background(), "/test.Test/Echo", wrapperspb.String(tt.request), new(wrapperspb.StringValue))
+		if s, _ := status.FromError(err); s.Code() != tt.code {
+			t.Errorf("%s: expected %v, got %v", tt.request, tt.code, err)
+		}
+	}
+}
+
+func TestStreamServer(t *testing.T) {
+	conn := startTestServer(t)
+	desc := &testServiceDesc.Streams[0]
+
+	stream, err := conn.NewStream(context.Background(), desc, "/test.Test/Count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.SendMsg(wrapperspb.Int64(3))
+	stream.CloseSend()
+	var got []int64
+	for {
+		v := new(wrapperspb.Int64Value)
+		if err := stream.RecvMsg(v); err != nil {
+			if err != io.EOF {
+				t.Fatalf("RecvMsg failed: %v", err)
+			}
+			break
+		}
+		got = append(got, v.GetValue())
+	}
+	if len(got) != 3 || got[2] != 3 {
+		t.Errorf("Unexpected stream: %v", got)
+	}
+
+	// 流中返回的业务错误在客户端还原
+	stream, _ = conn.NewStream(context.Background(), desc, "/test.Test/Count")
+	stream.SendMsg(wrapperspb.Int64(-1))
+	stream.CloseSend()
+	err = stream.RecvMsg(new(wrapperspb.Int64Value))
+	if !errors.Is(err, errOrderNotFound) {
+		t.Errorf("Expected errOrderNotFound, got %v", err)
+	}
+}
+
+func TestServerMetric(t *testing.T) {
+	metric.Init("0", "interceptor_test")
+	conn := startTestServer(t)
+	conn.Invoke(context.Background(), "/test.Test/Echo", wrapperspb.String("hi"), new(wrapperspb.StringValue))
+	conn.Invoke(context.Background(), "/test.Test/Echo", wrapperspb.String("notfound"), new(wrapperspb.StringValue))
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != metric.NameSpaceSugo+"_interceptor_test_grpc_h" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			got[labels["action"]+" "+labels["result"]] = m.GetHistogram().GetSampleCount()
+		}
+	}
+	for _, key := range []string{
+		"/test.Test/Echo success",
+		"/test.Test/Echo fail",
+		"client:/test.Test/Echo success",
+		"client:/test.Test/Echo fail",
+	} {
+		if got[key] != 1 {
+			t.Errorf("Expected 1 sample for %q, got %v", key, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/daozhonglee/go-util/api"
+	"github.com/daozhonglee/go-util/log"
+	"google.golang.org/grpc/metadata"
+)
+
+// TraceIDKey 链路ID在 gRPC metadata 中的 key，与 HTTP 的 api.TraceIDHeader 对应
+var TraceIDKey = "x-trace-id"
+
+// traceIncoming 从请求 metadata 获取链路ID（规则见 log.ParseTraceID，与 middleware.Trace 一致），没有时生成新的链路ID，写入 context 的 log.TRACEID
+func traceIncoming(ctx context.Context) (context.Context, string) {
+	traceID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		traceID = log.ParseTraceID(firstValue(md, TraceIDKey), firstValue(md, "traceparent"), firstValue(md, api.RequestIDHeader))
+	}
+	if traceID == "" {
+		traceID = log.NewTraceID()
+	}
+	return context.WithValue(ctx, log.TRACEID, traceID), traceID
+}
+
+// firstValue 返回 metadata 中 key 的第一个值，key 不区分大小写
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// traceOutgoing 将 context 中的链路ID写入请求 metadata，已有时不覆盖
+func traceOutgoing(ctx context.Context) context.Context {
+	traceID := log.TraceID(ctx)
+	if traceID == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(TraceIDKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, TraceIDKey, traceID)
+}
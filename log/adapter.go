@@ -1,6 +1,14 @@
 package log
 
-import "strings"
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
 
 // 全局单例实例
 var globalLoggerIFace = &LoggerIFace{}
@@ -23,3 +31,57 @@ func (*LoggerIFace) Printf(format string, v ...interface{}) {
 	format = strings.TrimRight(format, "\n")
 	Infof(format, v...)
 }
+
+// stdLogCallerSkip 标准库 log.Logger 的 Print 系列方法到 stdWriter.Write 的调用层数
+const stdLogCallerSkip = 3
+
+// StdLogger 返回写入 Logger 的标准库 log.Logger，每次输出按 level 级别记录一条日志，caller 为调用 log.Logger 的位置
+// 用于只接受 *log.Logger 的库，如 http.Server.ErrorLog = log.StdLogger(zap.ErrorLevel)
+func StdLogger(level zapcore.Level) *stdlog.Logger {
+	return stdlog.New(stdWriter{level: level}, "", 0)
+}
+
+type stdWriter struct {
+	level zapcore.Level
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	logger := CurrentLogger().Desugar().WithOptions(zap.AddCallerSkip(stdLogCallerSkip))
+	if ce := logger.Check(w.level, strings.TrimRight(string(p), "\n")); ce != nil {
+		ce.Write()
+	}
+	return len(p), nil
+}
+
+// RedisLogger go-redis 内部日志的适配，按 Warn 级别记录并带上 context 中的字段：
+//
+//	redis.SetLogger(log.NewRedisLogger())
+//
+// go-redis 的内部日志为连接池、重试等异常，默认直接输出到标准错误，delaytask 等使用 go-redis 的程序应在启动时设置
+type RedisLogger struct{}
+
+func NewRedisLogger() *RedisLogger {
+	return &RedisLogger{}
+}
+
+// Printf 实现 go-redis 的 internal.Logging 接口
+func (*RedisLogger) Printf(ctx context.Context, format string, v ...interface{}) {
+	// Ctx 跳过的一层调用即为 Printf，caller 为 go-redis 中调用的位置
+	l := Ctx(ctx)
+	if ce := l.logger.Check(zapcore.WarnLevel, format); ce != nil {
+		ce.Message = fmt.Sprintf(format, v...)
+		ce.Write(l.allFields(nil)...)
+	}
+}
+
+// PromLogger Prometheus promhttp.HandlerOpts.ErrorLog 的适配，按 Error 级别记录采集、输出指标时的错误
+type PromLogger struct{}
+
+func NewPromLogger() *PromLogger {
+	return &PromLogger{}
+}
+
+// Println 实现 promhttp.Logger 接口
+func (*PromLogger) Println(v ...interface{}) {
+	CurrentLogger().Desugar().WithOptions(zap.AddCallerSkip(1)).Error(strings.TrimRight(fmt.Sprintln(v...), "\n"))
+}
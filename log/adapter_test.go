@@ -0,0 +1,55 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestStdLogger(t *testing.T) {
+	logs := observeLogger(t)
+
+	std := StdLogger(zap.WarnLevel)
+	std.Printf("http: TLS handshake error from %s", "1.2.3.4")
+	std.Println("second")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 || entries[0].Message != "http: TLS handshake error from 1.2.3.4" || entries[1].Message != "second" {
+		t.Fatalf("Unexpected entries: %v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Level != zapcore.WarnLevel || !strings.HasSuffix(entry.Caller.File, "adapter_test.go") {
+			t.Errorf("Unexpected entry %v at %s", entry.Level, entry.Caller.File)
+		}
+	}
+}
+
+func TestRedisLogger(t *testing.T) {
+	logs := observeLogger(t)
+	ctx := context.WithValue(context.Background(), TRACEID, "trace-1")
+
+	NewRedisLogger().Printf(ctx, "redis: connection pool: %s", "timeout")
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Level != zapcore.WarnLevel || entries[0].Message != "redis: connection pool: timeout" {
+		t.Fatalf("Unexpected entries: %v", entries)
+	}
+	if entries[0].ContextMap()[TRACEID] != "trace-1" || !strings.HasSuffix(entries[0].Caller.File, "adapter_test.go") {
+		t.Errorf("Unexpected entry: %v at %s", entries[0].ContextMap(), entries[0].Caller.File)
+	}
+}
+
+func TestPromLogger(t *testing.T) {
+	logs := observeLogger(t)
+
+	NewPromLogger().Println("error gathering metrics:", "collector failed")
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Level != zapcore.ErrorLevel || entries[0].Message != "error gathering metrics: collector failed" {
+		t.Fatalf("Unexpected entries: %v", entries)
+	}
+	if !strings.HasSuffix(entries[0].Caller.File, "adapter_test.go") {
+		t.Errorf("Unexpected caller: %s", entries[0].Caller.File)
+	}
+}
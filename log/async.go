@@ -0,0 +1,221 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 异步写入缓冲区满时的处理策略
+const (
+	OverflowDrop  = "drop"  // 丢弃新的日志，通过 SetDropReporter 统计，原因为 DropReasonOverflow
+	OverflowBlock = "block" // 阻塞等待缓冲区有空位
+)
+
+// defaultAsyncSize 未指定缓冲区大小时缓存的日志条数
+const defaultAsyncSize = 1024
+
+// asyncBatchSize 后台写入时合并的最大字节数，避免单次写入超过 lumberjack 的文件大小限制
+const asyncBatchSize = 256 * 1024
+
+// AsyncOption 异步写入配置，Size 为0时同步写入
+// 日志先写入大小为 Size 条的环形缓冲区，后台协程每 FlushInterval 秒或缓冲区过半时批量写入文件
+type AsyncOption struct {
+	Size          int    `yaml:"size" json:"size"`                     // 缓冲区最多缓存的日志条数
+	Policy        string `yaml:"policy" json:"policy"`                 // 缓冲区满时的处理策略，drop 或 block，默认 drop
+	FlushInterval int    `yaml:"flush_interval" json:"flush_interval"` // 定时写入的周期（秒），默认1秒
+}
+
+// WithAsync 开启异步写入，只对写入文件的日志生效，Debug 模式写入标准输出时仍为同步写入
+func WithAsync(size int, policy string, flushInterval int) LogOption {
+	return func(opts *Option) {
+		opts.Async = AsyncOption{Size: size, Policy: policy, FlushInterval: flushInterval}
+	}
+}
+
+// AsyncWriter 带环形缓冲区的异步 WriteSyncer，Write 只复制数据到缓冲区，由后台协程写入 ws
+// Sync 等待缓冲区中的日志全部写入后调用 ws.Sync，程序退出前需要调用 Sync（Init 返回的 flush）或 Stop
+type AsyncWriter struct {
+	ws     zapcore.WriteSyncer
+	policy string
+	level  zapcore.Level // 丢弃日志时上报的级别，为对应日志文件的级别
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    [][]byte
+	head    int
+	n       int
+	stopped bool
+
+	wake    chan struct{}
+	syncReq chan chan error
+	done    chan struct{}
+	exited  chan struct{}
+	dropped atomic.Uint64
+	batch   []byte // 以下字段只在后台协程中访问
+	lastErr error  // 后台写入的错误，由下一次 Sync 返回
+}
+
+// NewAsyncWriter 创建异步写入，Size 为0时使用1024
+func NewAsyncWriter(ws zapcore.WriteSyncer, opt AsyncOption) *AsyncWriter {
+	return newAsyncWriter(ws, opt, zapcore.InfoLevel)
+}
+
+func newAsyncWriter(ws zapcore.WriteSyncer, opt AsyncOption, level zapcore.Level) *AsyncWriter {
+	if opt.Size <= 0 {
+		opt.Size = defaultAsyncSize
+	}
+	interval := time.Duration(opt.FlushInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	a := &AsyncWriter{
+		ws:      ws,
+		policy:  opt.Policy,
+		level:   level,
+		ring:    make([][]byte, opt.Size),
+		wake:    make(chan struct{}, 1),
+		syncReq: make(chan chan error),
+		done:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	a.notFull = sync.NewCond(&a.mu)
+	go a.run(interval)
+	return a
+}
+
+// asyncWriteSyncer 按 opt.Async 包装异步写入，未开启时原样返回，日志被 Init 替换后停止后台协程
+func asyncWriteSyncer(ws zapcore.WriteSyncer, opt Option, level zapcore.Level) zapcore.WriteSyncer {
+	if opt.Async.Size <= 0 {
+		return ws
+	}
+	a := newAsyncWriter(ws, opt.Async, level)
+	opt.closers.add(a.Stop)
+	return a
+}
+
+// Write 复制 p 到缓冲区，缓冲区满时按策略丢弃或阻塞
+// Stop 之后 ws 可能已被关闭，写入会被丢弃并通过 SetDropReporter 统计，原因为 DropReasonStopped
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	for !a.stopped && a.n == len(a.ring) {
+		if a.policy != OverflowBlock {
+			a.mu.Unlock()
+			a.dropped.Add(1)
+			reportDrop(a.level, DropReasonOverflow)
+			return len(p), nil
+		}
+		a.signal()
+		a.notFull.Wait()
+	}
+	if a.stopped {
+		a.mu.Unlock()
+		a.dropped.Add(1)
+		reportDrop(a.level, DropReasonStopped)
+		return len(p), nil
+	}
+
+	i := (a.head + a.n) % len(a.ring)
+	a.ring[i] = append(a.ring[i][:0], p...)
+	a.n++
+	if a.n >= len(a.ring)/2 {
+		a.signal()
+	}
+	a.mu.Unlock()
+	return len(p), nil
+}
+
+// Sync 等待缓冲区中的日志写入 ws 后调用 ws.Sync，返回此前后台写入的错误
+func (a *AsyncWriter) Sync() error {
+	ack := make(chan error, 1)
+	select {
+	case a.syncReq <- ack:
+		return <-ack
+	case <-a.exited:
+		return nil // Stop 时已经 Sync
+	}
+}
+
+// Stop 写入缓冲区中的日志并停止后台协程，之后的 Write 被丢弃，可重复调用
+func (a *AsyncWriter) Stop() error {
+	a.mu.Lock()
+	if !a.stopped {
+		a.stopped = true
+		close(a.done)
+		a.notFull.Broadcast()
+	}
+	a.mu.Unlock()
+	<-a.exited
+	return a.ws.Sync()
+}
+
+// Dropped 返回因缓冲区满或 Stop 之后写入被丢弃的日志条数
+func (a *AsyncWriter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+func (a *AsyncWriter) signal() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (a *AsyncWriter) run(interval time.Duration) {
+	defer close(a.exited)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.wake:
+			a.flush()
+		case <-ticker.C:
+			a.flush()
+		case ack := <-a.syncReq:
+			a.flush()
+			err := a.lastErr
+			a.lastErr = nil
+			if syncErr := a.ws.Sync(); err == nil {
+				err = syncErr
+			}
+			ack <- err
+		case <-a.done:
+			a.flush()
+			if a.lastErr != nil {
+				fmt.Fprintf(os.Stderr, "log: async write failed: %v\n", a.lastErr)
+			}
+			return
+		}
+	}
+}
+
+// flush 将缓冲区中的日志写入 ws，每次合并不超过 asyncBatchSize 的日志写入一次，写入时不持有锁，不阻塞 Write
+func (a *AsyncWriter) flush() {
+	for {
+		a.mu.Lock()
+		if a.n == 0 {
+			a.mu.Unlock()
+			return
+		}
+		a.batch = a.batch[:0]
+		for a.n > 0 && (len(a.batch) == 0 || len(a.batch)+len(a.ring[a.head]) <= asyncBatchSize) {
+			a.batch = append(a.batch, a.ring[a.head]...)
+			if cap(a.ring[a.head]) > asyncBatchSize {
+				a.ring[a.head] = nil // 不长期占用超大日志的内存
+			}
+			a.head = (a.head + 1) % len(a.ring)
+			a.n--
+		}
+		a.notFull.Broadcast()
+		a.mu.Unlock()
+
+		if _, err := a.ws.Write(a.batch); err != nil && a.lastErr == nil {
+			a.lastErr = err
+		}
+	}
+}
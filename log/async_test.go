@@ -0,0 +1,207 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// memSyncer 记录写入的数据，gate 不为nil时每次写入前等待
+type memSyncer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+	syncs  int
+	gate   chan struct{}
+}
+
+func (m *memSyncer) Write(p []byte) (int, error) {
+	if m.gate != nil {
+		<-m.gate
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.writes++
+	return m.buf.Write(p)
+}
+
+func (m *memSyncer) Sync() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.syncs++
+	return nil
+}
+
+func (m *memSyncer) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.buf.String()
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	ws := &memSyncer{}
+	a := NewAsyncWriter(ws, AsyncOption{Size: 100, FlushInterval: 60})
+	defer a.Stop()
+
+	var expected strings.Builder
+	for i := 0; i < 10; i++ {
+		line := strings.Repeat(string(rune('a'+i)), 3) + "\n"
+		a.Write([]byte(line))
+		expected.WriteString(line)
+	}
+	if err := a.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if ws.String() != expected.String() {
+		t.Errorf("Expected %q, got %q", expected.String(), ws.String())
+	}
+	// 缓冲区中的日志合并写入
+	if ws.writes != 1 || ws.syncs != 1 {
+		t.Errorf("Expected 1 write and 1 sync, got %d and %d", ws.writes, ws.syncs)
+	}
+}
+
+func TestAsyncWriterFlushInterval(t *testing.T) {
+	ws := &memSyncer{}
+	a := NewAsyncWriter(ws, AsyncOption{Size: 100, FlushInterval: 1})
+	defer a.Stop()
+
+	a.Write([]byte("tick\n"))
+	deadline := time.Now().Add(3 * time.Second)
+	for ws.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ws.String() != "tick\n" {
+		t.Errorf("Expected periodic flush, got %q", ws.String())
+	}
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+	drops := recordDrops(t)
+	ws := &memSyncer{gate: make(chan struct{})}
+	a := newAsyncWriter(ws, AsyncOption{Size: 2, Policy: OverflowDrop, FlushInterval: 60}, zap.WarnLevel)
+
+	// 后台协程阻塞在第一次写入，之后缓冲区满
+	for i := 0; i < 10; i++ {
+		a.Write([]byte("x\n"))
+	}
+	close(ws.gate)
+	a.Stop()
+
+	written := strings.Count(ws.String(), "x\n")
+	if written+int(a.Dropped()) != 10 || a.Dropped() == 0 {
+		t.Errorf("Expected dropped entries, written %d, dropped %d", written, a.Dropped())
+	}
+	if got := drops()["warn overflow"]; uint64(got) != a.Dropped() {
+		t.Errorf("Expected %d reported drops, got %v", a.Dropped(), drops())
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	ws := &memSyncer{gate: make(chan struct{})}
+	a := NewAsyncWriter(ws, AsyncOption{Size: 2, Policy: OverflowBlock, FlushInterval: 60})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			a.Write([]byte("x\n"))
+		}
+	}()
+	select {
+	case <-done:
+		t.Fatal("Expected Write to block while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(ws.gate)
+	<-done
+	a.Stop()
+	if got := strings.Count(ws.String(), "x\n"); got != 10 || a.Dropped() != 0 {
+		t.Errorf("Expected all 10 entries, got %d, dropped %d", got, a.Dropped())
+	}
+}
+
+func TestAsyncWriterStop(t *testing.T) {
+	drops := recordDrops(t)
+	ws := &memSyncer{}
+	a := NewAsyncWriter(ws, AsyncOption{Size: 100, FlushInterval: 60})
+	a.Write([]byte("a\n"))
+	a.Stop()
+	if n, err := a.Write([]byte("b\n")); n != 2 || err != nil {
+		t.Errorf("Expected write after Stop to be dropped silently, got %d %v", n, err)
+	}
+	if err := a.Sync(); err != nil || a.Stop() != nil {
+		t.Errorf("Expected Sync and Stop to succeed after Stop: %v", err)
+	}
+	if ws.String() != "a\n" {
+		t.Errorf("Expected writes after Stop to be dropped, got %q", ws.String())
+	}
+	if a.Dropped() != 1 || drops()["info stopped"] != 1 {
+		t.Errorf("Expected 1 dropped entry, got %d %v", a.Dropped(), drops())
+	}
+}
+
+func TestAsyncLogger(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewLogger(WithDir(dir), WithAppName("app"), WithAsync(100, OverflowBlock, 60))
+	logger.Info("async entry")
+	logger.Error("async error")
+
+	if err := syncLoggers(logger); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	data, _ := os.ReadFile(dir + "/app.INFO")
+	if !strings.Contains(string(data), "async entry") || !strings.Contains(string(data), "async error") {
+		t.Errorf("Expected entries to be flushed on Sync, got %q", data)
+	}
+	if data, _ := os.ReadFile(dir + "/app.ERROR"); !strings.Contains(string(data), "async error") {
+		t.Errorf("Expected error entry to be flushed on Sync, got %q", data)
+	}
+}
+
+func TestInitStopsReplacedAsyncWriters(t *testing.T) {
+	restoreAfterInit(t)
+
+	dir := t.TempDir()
+	opts := []LogOption{WithDir(dir), WithAppName("app"), WithAsync(100, OverflowDrop, 60)}
+	if _, err := Init(opts...); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	first := globals.Load()
+	Info("before replace")
+	base := runtime.NumGoroutine()
+	for i := 0; i < 5; i++ {
+		if _, err := Init(opts...); err != nil {
+			t.Fatalf("Init failed: %v", err)
+		}
+	}
+	// 每次 Init 创建4个异步写入，未停止时协程数增加20
+	if n := runtime.NumGoroutine(); n > base+2 {
+		t.Errorf("Expected replaced async writers to stop, goroutines %d -> %d", base, n)
+	}
+
+	// 替换前取得旧日志的 goroutine 写入时被丢弃，不会重新打开已关闭的文件
+	drops := recordDrops(t)
+	first.logger.Info("after replace")
+	data, _ := os.ReadFile(dir + "/app.INFO")
+	if !strings.Contains(string(data), "before replace") || strings.Contains(string(data), "after replace") {
+		t.Errorf("Expected only entries written before replace, got %q", data)
+	}
+	if drops()["info stopped"] == 0 {
+		t.Errorf("Expected writes of the replaced logger to be dropped, got %v", drops())
+	}
+}
+
+func TestAsyncOptionValidate(t *testing.T) {
+	err := Option{Async: AsyncOption{Size: -1, Policy: "wait"}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "async.size") || !strings.Contains(err.Error(), "async.policy") {
+		t.Errorf("Expected async errors, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type fieldsKey struct{}
+
+var (
+	contextKeysMu sync.RWMutex
+	contextKeys   = []contextKey{
+		{key: TRACEID, field: TRACEID},
+		{key: SPANID, field: SPANID},
+		{key: USERID, field: USERID},
+	}
+)
+
+type contextKey struct {
+	key   interface{}
+	field string
+}
+
+// RegisterContextKey 注册需要输出到日志的 context key，Ctx 会将 ctx.Value(key) 作为名为 field 的字段输出
+// 内置 TRACEID、SPANID、USERID，一般在 init 中调用；与 context.WithValue 一样，key 必须是可比较的类型
+func RegisterContextKey(key interface{}, field string) {
+	if key == nil {
+		panic("log: nil context key")
+	}
+	if !reflect.TypeOf(key).Comparable() {
+		panic("log: context key is not comparable")
+	}
+
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+
+	for i, k := range contextKeys {
+		if k.key == key {
+			contextKeys[i].field = field
+			return
+		}
+	}
+	contextKeys = append(contextKeys, contextKey{key: key, field: field})
+}
+
+// WithFields 返回附加了日志字段的 context，之后通过 Ctx 记录的日志都会带上这些字段
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	old, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(old)+len(fields))
+	merged = append(merged, old...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// ContextFields 返回 context 中的日志字段：已注册的 context key 和 WithFields 附加的字段
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []zap.Field
+	contextKeysMu.RLock()
+	for _, k := range contextKeys {
+		if v := ctx.Value(k.key); v != nil {
+			fields = append(fields, zap.Any(k.field, v))
+		}
+	}
+	contextKeysMu.RUnlock()
+
+	if attached, ok := ctx.Value(fieldsKey{}).([]zap.Field); ok {
+		fields = append(fields, attached...)
+	}
+	return fields
+}
+
+// CtxLogger 带 context 字段的结构化日志
+// context 字段在写入时才读取和编码，日志级别未开启时没有额外开销
+type CtxLogger struct {
+	logger *zap.Logger
+	ctx    context.Context
+	fields []zap.Field // With 附加的字段
+}
+
+// Ctx 返回带 context 字段的结构化日志，字段见 ContextFields：
+//
+//	log.Ctx(ctx).Info("order created", zap.Int64("order_id", id))
+func Ctx(ctx context.Context) *CtxLogger {
+	return &CtxLogger{logger: globals.Load().structured, ctx: ctx}
+}
+
+// With 返回附加了字段的日志
+func (l *CtxLogger) With(fields ...zap.Field) *CtxLogger {
+	merged := make([]zap.Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &CtxLogger{logger: l.logger, ctx: l.ctx, fields: merged}
+}
+
+// allFields 按 context 字段、With 附加的字段、调用时的字段的顺序合并
+func (l *CtxLogger) allFields(fields []zap.Field) []zap.Field {
+	ctxFields := ContextFields(l.ctx)
+	if len(ctxFields) == 0 && len(l.fields) == 0 {
+		return fields
+	}
+	all := make([]zap.Field, 0, len(ctxFields)+len(l.fields)+len(fields))
+	all = append(all, ctxFields...)
+	all = append(all, l.fields...)
+	return append(all, fields...)
+}
+
+func (l *CtxLogger) Debug(msg string, fields ...zap.Field) {
+	if ce := l.logger.Check(zapcore.DebugLevel, msg); ce != nil {
+		ce.Write(l.allFields(fields)...)
+	}
+}
+
+func (l *CtxLogger) Info(msg string, fields ...zap.Field) {
+	if ce := l.logger.Check(zapcore.InfoLevel, msg); ce != nil {
+		ce.Write(l.allFields(fields)...)
+	}
+}
+
+func (l *CtxLogger) Warn(msg string, fields ...zap.Field) {
+	if ce := l.logger.Check(zapcore.WarnLevel, msg); ce != nil {
+		ce.Write(l.allFields(fields)...)
+	}
+}
+
+func (l *CtxLogger) Error(msg string, fields ...zap.Field) {
+	if ce := l.logger.Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(l.allFields(fields)...)
+	}
+}
+
+// Zap 返回附加了 context 字段的底层 *zap.Logger，调用位置不需要跳过
+func (l *CtxLogger) Zap() *zap.Logger {
+	return l.logger.WithOptions(zap.AddCallerSkip(-1)).With(l.allFields(nil)...)
+}
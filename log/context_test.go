@@ -0,0 +1,104 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLogger 将 Logger 替换为可观察的 logger，测试结束后恢复
+func observeLogger(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	t.Cleanup(ReplaceLoggers(zap.New(core, zap.AddCaller()).Sugar(), nil))
+	return logs
+}
+
+type tenantKey struct{}
+
+func TestCtx(t *testing.T) {
+	logs := observeLogger(t)
+	RegisterContextKey(tenantKey{}, "tenant")
+
+	ctx := context.WithValue(context.Background(), TRACEID, "trace-1")
+	ctx = context.WithValue(ctx, USERID, int64(42))
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	ctx = WithFields(ctx, zap.String("order_id", "o-1"))
+	ctx = WithFields(ctx, zap.String("step", "pay"))
+
+	Ctx(ctx).With(zap.Int("attempt", 2)).Info("order paid", zap.Int64("amount", 100))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	expected := map[string]interface{}{
+		"trace_id": "trace-1",
+		"user_id":  int64(42),
+		"tenant":   "acme",
+		"order_id": "o-1",
+		"step":     "pay",
+		"attempt":  int64(2),
+		"amount":   int64(100),
+	}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Errorf("Expected field %s=%v, got %v", k, v, fields[k])
+		}
+	}
+	if _, ok := fields["span_id"]; ok {
+		t.Error("Expected span_id to be absent")
+	}
+	if !strings.HasSuffix(entries[0].Caller.File, "context_test.go") {
+		t.Errorf("Expected caller in test file, got %s", entries[0].Caller.File)
+	}
+}
+
+func TestCtxNil(t *testing.T) {
+	logs := observeLogger(t)
+
+	Ctx(nil).Warn("no context")
+	if logs.Len() != 1 || len(logs.All()[0].Context) != 0 {
+		t.Errorf("Unexpected entries: %v", logs.All())
+	}
+}
+
+// countingContext 记录 Value 的调用次数
+type countingContext struct {
+	context.Context
+	calls int
+}
+
+func (c *countingContext) Value(key interface{}) interface{} {
+	c.calls++
+	return c.Context.Value(key)
+}
+
+func TestCtxDisabledLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	t.Cleanup(ReplaceLoggers(zap.New(core).Sugar(), nil))
+
+	ctx := &countingContext{Context: context.WithValue(context.Background(), TRACEID, "trace-1")}
+	Ctx(ctx).With(zap.String("step", "pay")).Debug("skipped")
+	if ctx.calls != 0 || logs.Len() != 0 {
+		t.Errorf("Expected disabled level not to read context, got %d calls", ctx.calls)
+	}
+
+	Ctx(ctx).Info("written")
+	if ctx.calls == 0 || logs.Len() != 1 || logs.All()[0].ContextMap()["trace_id"] != "trace-1" {
+		t.Errorf("Unexpected entries: %v", logs.All())
+	}
+}
+
+func TestRegisterContextKeyNotComparable(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for non-comparable key")
+		}
+	}()
+	RegisterContextKey([]string{"tenant"}, "tenant")
+}
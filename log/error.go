@@ -0,0 +1,64 @@
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Err 返回 error 字段，错误携带调用栈时（errorutil.WithStack、errorutil.Errorf 等）
+// 调用栈作为结构化的 stack 数组输出，而不是拼接在错误信息里
+func Err(err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object("error", errorObject{err: err})
+}
+
+type errorObject struct {
+	err error
+}
+
+func (e errorObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", e.err.Error())
+	if be, ok := errorutil.As(e.err); ok {
+		enc.AddInt("code", be.Code)
+	}
+	if frames := errorutil.Frames(e.err); len(frames) > 0 {
+		return enc.AddArray("stack", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, frame := range frames {
+				if err := arr.AppendObject(frameObject(frame)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	return nil
+}
+
+type frameObject errorutil.Frame
+
+func (f frameObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("function", f.Function)
+	enc.AddString("file", f.File)
+	enc.AddInt("line", f.Line)
+	return nil
+}
+
+// ErrorStack 输出错误日志，err 通过 Err 作为结构化字段输出
+func ErrorStack(err error, format string, a ...interface{}) {
+	CurrentLogger().Errorw(fmt.Sprintf(format, a...), Err(err))
+}
+
+// ErrorStackx 同 ErrorStack，并输出 context 中的 TraceID
+func ErrorStackx(ctx context.Context, err error, format string, a ...interface{}) {
+	if ctx == nil {
+		ErrorStack(err, format, a...)
+		return
+	}
+	CurrentLogger().Errorw(fmt.Sprintf(format, a...), TRACEID, ctx.Value(TRACEID), Err(err))
+}
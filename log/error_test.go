@@ -0,0 +1,46 @@
+package log
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestErr(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	err := errorutil.Wrap(errorutil.WithStack(errors.New("dial tcp")), 1001, "操作失败")
+	logger.Error("load user failed", Err(err))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	obj, ok := fields["error"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected error field to be an object, got %T", fields["error"])
+	}
+	if obj["message"] != "操作失败: dial tcp" || obj["code"] != 1001 {
+		t.Errorf("Unexpected error field: %v", obj)
+	}
+	stack, ok := obj["stack"].([]interface{})
+	if !ok || len(stack) == 0 {
+		t.Fatalf("Expected stack array, got %v", obj["stack"])
+	}
+	frame := stack[0].(map[string]interface{})
+	if frame["function"] != "github.com/daozhonglee/go-util/log.TestErr" {
+		t.Errorf("Unexpected first frame: %v", frame)
+	}
+}
+
+func TestErrNil(t *testing.T) {
+	if Err(nil).Type != zapcore.SkipType {
+		t.Error("Expected nil error to be skipped")
+	}
+}
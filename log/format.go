@@ -4,106 +4,122 @@ import (
 	"context"
 	"fmt"
 	"os"
+
+	"go.uber.org/zap/zapcore"
 )
 
+// logf 以格式化模板作为消息检查日志，采样按模板和级别计数，通过检查后才格式化消息
+// 只能由 Infof 等导出函数直接调用，caller 跳过 logf 和调用它的函数
+func logf(level zapcore.Level, format string, a []interface{}) {
+	if ce := globals.Load().formatter.Check(level, format); ce != nil {
+		ce.Message = formatMessage(format, a)
+		ce.Write()
+	}
+}
+
+// formatMessage 与 zap.SugaredLogger 的 Infof 等格式化规则一致
+func formatMessage(format string, a []interface{}) string {
+	if len(a) == 0 {
+		return format
+	}
+	if format != "" {
+		return fmt.Sprintf(format, a...)
+	}
+	if len(a) == 1 {
+		if s, ok := a[0].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprint(a...)
+}
+
 func CRITICAL(format string, a ...interface{}) {
 	defer func() {
 		if err := recover(); err != nil {
 			datastr := fmt.Sprintf(format, a...)
-			Logger.Error(datastr)
+			CurrentLogger().Error(datastr)
 		}
 	}()
-	Logger.Panicf(format, a...)
+	CurrentLogger().Panicf(format, a...)
 }
 
 func Debug(a ...interface{}) {
-	Logger.Debug(a...)
+	CurrentLogger().Debug(a...)
 }
 
 func Debugf(format string, a ...interface{}) {
-	Logger.Debugf(format, a...)
+	logf(zapcore.DebugLevel, format, a)
 }
 func Info(a ...interface{}) {
-	Logger.Info(a...)
+	CurrentLogger().Info(a...)
 }
 
 func Infof(format string, a ...interface{}) {
-	Logger.Infof(format, a...)
+	logf(zapcore.InfoLevel, format, a)
 }
 
 func Warn(a ...interface{}) {
-	Logger.Warn(a...)
+	CurrentLogger().Warn(a...)
 }
 
 func Warnf(format string, a ...interface{}) {
-	Logger.Warnf(format, a...)
+	logf(zapcore.WarnLevel, format, a)
 }
 
 func Error(a ...interface{}) {
-	Logger.Error(a...)
+	CurrentLogger().Error(a...)
 }
 
 func Errorf(format string, a ...interface{}) {
-	Logger.Errorf(format, a...)
+	logf(zapcore.ErrorLevel, format, a)
 }
 
 func Fatal(a ...interface{}) {
-	Logger.Fatal(a...)
+	CurrentLogger().Fatal(a...)
 }
 
 func Fatalf(format string, a ...interface{}) {
-	Logger.Fatalf(format, a...)
+	CurrentLogger().Fatalf(format, a...)
 }
 
 func FatalfWithExit(format string, a ...interface{}) {
-	Logger.Fatalf(format, a...)
+	CurrentLogger().Fatalf(format, a...)
 	os.Exit(1)
 }
 
 func Panic(a ...interface{}) {
-	Logger.Panic(a...)
+	CurrentLogger().Panic(a...)
 }
 
 func Panicf(format string, a ...interface{}) {
-	Logger.Panicf(format, a...)
+	CurrentLogger().Panicf(format, a...)
 }
 
-func Debugx(ctx context.Context, format string, a ...interface{}) {
+// traceFormat 在格式化模板前加上 ctx 中的链路ID，ctx 为nil时不变
+// Debugx 等必须自己调用 logf，不能经过 Debugf，否则 caller 会多跳过一层
+func traceFormat(ctx context.Context, format string, a []interface{}) (string, []interface{}) {
 	if ctx == nil {
-		Debugf(format, a...)
-		return
+		return format, a
 	}
-	format = "[TRACEID:%v] " + format
-	a = append([]interface{}{ctx.Value(TRACEID)}, a...)
-	Logger.Debugf(format, a...)
+	return "[TRACEID:%v] " + format, append([]interface{}{ctx.Value(TRACEID)}, a...)
+}
+
+func Debugx(ctx context.Context, format string, a ...interface{}) {
+	format, a = traceFormat(ctx, format, a)
+	logf(zapcore.DebugLevel, format, a)
 }
 
 func Infox(ctx context.Context, format string, a ...interface{}) {
-	if ctx == nil {
-		Infof(format, a...)
-		return
-	}
-	format = "[TRACEID:%v] " + format
-	a = append([]interface{}{ctx.Value(TRACEID)}, a...)
-	Logger.Infof(format, a...)
+	format, a = traceFormat(ctx, format, a)
+	logf(zapcore.InfoLevel, format, a)
 }
 
 func Warnx(ctx context.Context, format string, a ...interface{}) {
-	if ctx == nil {
-		Warnf(format, a...)
-		return
-	}
-	format = "[TRACEID:%v] " + format
-	a = append([]interface{}{ctx.Value(TRACEID)}, a...)
-	Logger.Warnf(format, a...)
+	format, a = traceFormat(ctx, format, a)
+	logf(zapcore.WarnLevel, format, a)
 }
 
 func Errorx(ctx context.Context, format string, a ...interface{}) {
-	if ctx == nil {
-		Errorf(format, a...)
-		return
-	}
-	format = "[TRACEID:%v] " + format
-	a = append([]interface{}{ctx.Value(TRACEID)}, a...)
-	Logger.Errorf(format, a...)
+	format, a = traceFormat(ctx, format, a)
+	logf(zapcore.ErrorLevel, format, a)
 }
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFormatCaller(t *testing.T) {
+	logs := observeLogger(t)
+	ctx := context.WithValue(context.Background(), TRACEID, "trace-1")
+
+	Debugf("f %d", 1)
+	Infof("f %d", 2)
+	Warnf("f %d", 3)
+	Errorf("f %d", 4)
+	for _, c := range []context.Context{ctx, nil} {
+		Debugx(c, "x %d", 1)
+		Infox(c, "x %d", 2)
+		Warnx(c, "x %d", 3)
+		Errorx(c, "x %d", 4)
+	}
+
+	entries := logs.AllUntimed()
+	if len(entries) != 12 {
+		t.Fatalf("Expected 12 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Caller.File, "format_test.go") {
+			t.Errorf("Expected caller in test file for %q, got %s", entry.Message, entry.Caller.String())
+		}
+	}
+	if entries[4].Message != "[TRACEID:trace-1] x 1" || entries[8].Message != "x 1" {
+		t.Errorf("Unexpected messages: %q, %q", entries[4].Message, entries[8].Message)
+	}
+}
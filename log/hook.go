@@ -0,0 +1,169 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DropReasonHook 异步钩子的队列满被丢弃
+const DropReasonHook = "hook"
+
+// Hook 日志钩子，用于将错误日志转发到告警等外部系统，见 WithHook
+type Hook interface {
+	Fire(ent zapcore.Entry, fields []zapcore.Field) error
+}
+
+// HookFunc 函数形式的 Hook
+type HookFunc func(ent zapcore.Entry, fields []zapcore.Field) error
+
+func (f HookFunc) Fire(ent zapcore.Entry, fields []zapcore.Field) error {
+	return f(ent, fields)
+}
+
+// LevelHook Level 及以上级别的日志调用的钩子
+type LevelHook struct {
+	Level zapcore.Level
+	Hook  Hook
+}
+
+// WithHook 添加日志钩子，level 及以上级别的日志在写入时同步调用 hook.Fire，fields 包括 With 添加的字段
+// 耗时的操作应使用 NewWebhook 等异步钩子，避免阻塞写日志的协程
+func WithHook(level zapcore.Level, hook Hook) LogOption {
+	return func(opts *Option) {
+		opts.Hooks = append(opts.Hooks, LevelHook{Level: level, Hook: hook})
+	}
+}
+
+func hookCores(opt Option) []zapcore.Core {
+	cores := make([]zapcore.Core, 0, len(opt.Hooks))
+	for _, h := range opt.Hooks {
+		cores = append(cores, &hookCore{LevelEnabler: h.Level, hook: h.Hook})
+	}
+	return cores
+}
+
+// hookCore 调用钩子的 core
+type hookCore struct {
+	zapcore.LevelEnabler
+	hook   Hook
+	fields []zapcore.Field
+}
+
+func (c *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	return &hookCore{
+		LevelEnabler: c.LevelEnabler,
+		hook:         c.hook,
+		fields:       append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *hookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *hookCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.hook.Fire(ent, append(c.fields[:len(c.fields):len(c.fields)], fields...))
+}
+
+func (c *hookCore) Sync() error {
+	return nil
+}
+
+// Webhook 异步钩子，将日志按JSON格式 POST 到指定地址，如告警服务的接收地址
+// 队列满时丢弃并通过 SetDropReporter 统计，原因为 DropReasonHook；发送失败时输出到标准错误
+type Webhook struct {
+	url    string
+	client *http.Client
+	enc    zapcore.Encoder
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan []byte
+	exited chan struct{}
+}
+
+// NewWebhook 创建异步钩子，size 为队列长度，timeout 为每次请求的超时时间：
+//
+//	hook := log.NewWebhook("http://alert.internal/api/log", 100, 3*time.Second)
+//	defer hook.Close()
+//	log.Init(log.WithHook(zap.ErrorLevel, hook))
+func NewWebhook(url string, size int, timeout time.Duration) *Webhook {
+	config := zap.NewProductionEncoderConfig()
+	config.EncodeTime = zapcore.ISO8601TimeEncoder
+	w := &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		enc:    zapcore.NewJSONEncoder(config),
+		queue:  make(chan []byte, size),
+		exited: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Fire 编码日志后放入队列，Close 之后忽略
+func (w *Webhook) Fire(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := w.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	body := append([]byte(nil), buf.Bytes()...)
+	buf.Free()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return nil
+	}
+	select {
+	case w.queue <- body:
+	default:
+		reportDrop(ent.Level, DropReasonHook)
+	}
+	return nil
+}
+
+// Close 等待队列中的日志发送完成后停止，可重复调用
+func (w *Webhook) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.exited
+	return nil
+}
+
+func (w *Webhook) run() {
+	defer close(w.exited)
+	for body := range w.queue {
+		if err := w.post(body); err != nil {
+			fmt.Fprintf(os.Stderr, "log: webhook %s: %v\n", w.url, err)
+		}
+	}
+}
+
+func (w *Webhook) post(body []byte) error {
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
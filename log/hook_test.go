@@ -0,0 +1,89 @@
+package log
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestHook(t *testing.T) {
+	var mu sync.Mutex
+	var fired []string
+	hook := HookFunc(func(ent zapcore.Entry, fields []zapcore.Field) error {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, f := range fields {
+			f.AddTo(enc)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fired = append(fired, ent.Message+" "+enc.Fields["order"].(string))
+		return nil
+	})
+
+	logger := NewLogger(WithDebug(true), WithHook(zap.ErrorLevel, hook)).With(zap.String("order", "o-1"))
+	logger.Warn("warn")
+	logger.Error("error")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fired) != 1 || fired[0] != "error o-1" {
+		t.Errorf("Expected hook for error entry with fields, got %v", fired)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	bodies := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer server.Close()
+
+	hook := NewWebhook(server.URL, 10, time.Second)
+	logger := NewLogger(WithDebug(true), WithHook(zap.ErrorLevel, hook))
+	logger.Error("payment failed", zap.String("order", "o-1"))
+	hook.Close()
+	hook.Close()
+
+	select {
+	case body := <-bodies:
+		if !strings.Contains(body, `"msg":"payment failed"`) || !strings.Contains(body, `"order":"o-1"`) {
+			t.Errorf("Unexpected body: %s", body)
+		}
+	default:
+		t.Fatal("Expected webhook request before Close returns")
+	}
+
+	// Close 之后忽略
+	if err := hook.Fire(zapcore.Entry{Level: zap.ErrorLevel}, nil); err != nil {
+		t.Errorf("Expected Fire after Close to be ignored: %v", err)
+	}
+}
+
+func TestWebhookDrop(t *testing.T) {
+	drops := recordDrops(t)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	hook := NewWebhook(server.URL, 1, 5*time.Second)
+	for i := 0; i < 5; i++ {
+		hook.Fire(zapcore.Entry{Level: zap.ErrorLevel, Message: "boom"}, nil)
+	}
+	close(release)
+	hook.Close()
+
+	// 1条发送中，1条在队列中，其余丢弃
+	if got := drops()["error hook"]; got < 3 {
+		t.Errorf("Expected at least 3 dropped entries, got %v", drops())
+	}
+}
@@ -0,0 +1,290 @@
+package log
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/daozhonglee/go-util/errorutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix LoadOption 读取环境变量的前缀，变量名为前缀加大写的 yaml 字段名，如 LOG_LEVEL、LOG_APP_NAME
+var EnvPrefix = "LOG_"
+
+var initMu sync.Mutex
+
+// closerList 创建日志时打开的资源，按创建的相反顺序关闭，先停止异步写入再关闭文件
+type closerList struct {
+	mu      sync.Mutex
+	closers []func() error
+}
+
+// add 记录需要关闭的资源，c 为nil时不记录，由调用方负责
+func (c *closerList) add(fn func() error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closers = append(c.closers, fn)
+}
+
+func (c *closerList) close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	closers := c.closers
+	c.closers = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithOption 使用完整的配置，一般与 LoadOption 配合使用
+func WithOption(o Option) LogOption {
+	return func(opts *Option) {
+		*opts = o
+	}
+}
+
+// Init 按配置创建日志并原子替换全局的 Logger、DataLogger，Infof、Ctx、ILogger() 和 NewSugoWriter() 等随之使用新的日志
+// 配置校验失败或创建失败时返回错误，全局日志保持不变；替换后旧的日志会被 Sync，之前 Init 创建的文件、异步写入的协程会被关闭
+// 未指定 AtomicLevel 时使用全局的 Level()，LevelHandler 等运行时调整继续生效，再次 Init 时也不会被配置的 Level 覆盖
+// 返回的 flush 用于退出前写入缓冲的日志：
+//
+//	flush, err := log.Init(log.WithAppName("order"), log.WithDir("/data/logs"), log.WithLevel(zap.InfoLevel))
+//	defer flush()
+//
+// 其他 goroutine 通过包内函数或 CurrentLogger() 记录日志时可以并发调用 Init，直接读取 Logger 变量的代码应在 Init 之后启动
+func Init(opts ...LogOption) (flush func() error, err error) {
+	opt := Option{}
+	for _, o := range opts {
+		o(&opt)
+	}
+	if err := opt.Validate(); err != nil {
+		return nil, err
+	}
+	if opt.AtomicLevel == (zap.AtomicLevel{}) {
+		opt.AtomicLevel = rootLevel
+		if current := rootLevel.Level(); current != configuredLevel.Level() {
+			opt.Level = current // 运行时修改过，保留修改后的级别
+		} else {
+			defer func() {
+				if err == nil {
+					configuredLevel.SetLevel(opt.Level)
+				}
+			}()
+		}
+	}
+	if !opt.Debug && opt.Dir != "" {
+		if err := os.MkdirAll(opt.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("log: create dir: %w", err)
+		}
+	}
+
+	var logger, dataLogger *zap.Logger
+	opt.closers = &closerList{}
+	err = errorutil.Try(func() error {
+		logger = NewLogger(WithOption(opt))
+		dataLogger = NewDataLogger(WithOption(opt))
+		return nil
+	})
+	if err != nil {
+		opt.closers.close()
+		return nil, fmt.Errorf("log: init: %w", err)
+	}
+
+	g := newGlobalLoggers(logger.Sugar(), dataLogger.Sugar())
+	g.closers = opt.closers
+	old := swapLoggers(g)
+	syncLoggers(old.logger.Desugar(), old.dataLogger.Desugar())
+	// 替换前已取得旧日志的 goroutine 仍可能写入，异步写入停止后这些日志被丢弃，不会重新打开已关闭的文件
+	if err := old.closers.close(); err != nil {
+		fmt.Fprintf(os.Stderr, "log: close replaced logger: %v\n", err)
+	}
+	return func() error {
+		return syncLoggers(logger, dataLogger)
+	}, nil
+}
+
+// InitFromConfig 从配置文件和环境变量加载配置后调用 Init，规则见 LoadOption
+func InitFromConfig(path string) (flush func() error, err error) {
+	opt, err := LoadOption(path)
+	if err != nil {
+		return nil, err
+	}
+	return Init(WithOption(opt))
+}
+
+// LoadOption 加载配置：先读取配置文件（.yaml/.yml/.json，path 为空时跳过），再用环境变量覆盖
+//
+//	app_name: order
+//	level: info
+//	dir: /data/logs
+//	max_size: 100
+func LoadOption(path string) (Option, error) {
+	opt := Option{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return opt, fmt.Errorf("log: read config: %w", err)
+		}
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &opt)
+		case ".json":
+			err = json.Unmarshal(data, &opt)
+		default:
+			err = fmt.Errorf("unsupported config format %q", ext)
+		}
+		if err != nil {
+			return opt, fmt.Errorf("log: parse config %s: %w", path, err)
+		}
+	}
+	if err := loadEnv(&opt); err != nil {
+		return opt, err
+	}
+	return opt, nil
+}
+
+// loadEnv 按 yaml 字段名读取环境变量，嵌套的配置使用下划线连接，如 LOG_SAMPLING_INITIAL
+func loadEnv(opt *Option) error {
+	return loadEnvStruct(reflect.ValueOf(opt).Elem(), EnvPrefix)
+}
+
+func loadEnvStruct(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + strings.ToUpper(name)
+		if fv := rv.Field(i); fv.Kind() == reflect.Struct {
+			if err := loadEnvStruct(fv, key+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(rv.Field(i), value); err != nil {
+			return fmt.Errorf("log: invalid env %s=%q: %w", key, value, err)
+		}
+	}
+	return nil
+}
+
+func setEnvValue(fv reflect.Value, value string) error {
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+		// 逗号分隔，如 LOG_REDACT_FIELDS=password,token
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fv.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// Validate 校验配置，返回所有不合法的配置项
+func (o Option) Validate() error {
+	var errs []error
+	if o.Level < zapcore.DebugLevel || o.Level > zapcore.FatalLevel {
+		errs = append(errs, fmt.Errorf("invalid level %d", o.Level))
+	}
+	if o.Encoding != "" && o.Encoding != "json" && o.Encoding != "console" {
+		errs = append(errs, fmt.Errorf("invalid encoding %q, must be json or console", o.Encoding))
+	}
+	for _, field := range []struct {
+		name  string
+		value int64
+	}{
+		{"caller_skip", int64(o.CallerSkip)},
+		{"max_size", int64(o.MaxSize)},
+		{"max_backups", int64(o.MaxBackups)},
+		{"max_age", int64(o.MaxAge)},
+		{"rotation_time", int64(o.RotationTime)},
+		{"rotation_size", o.RotationSize},
+		{"rotation_count", int64(o.RotationCount)},
+		{"rotation_max_age", int64(o.RotationMaxAge)},
+		{"sampling.interval", int64(o.Sampling.Interval)},
+		{"sampling.initial", int64(o.Sampling.Initial)},
+		{"sampling.thereafter", int64(o.Sampling.Thereafter)},
+		{"async.size", int64(o.Async.Size)},
+		{"async.flush_interval", int64(o.Async.FlushInterval)},
+	} {
+		if field.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", field.name, field.value))
+		}
+	}
+	if o.Async.Policy != "" && o.Async.Policy != OverflowDrop && o.Async.Policy != OverflowBlock {
+		errs = append(errs, fmt.Errorf("invalid async.policy %q, must be drop or block", o.Async.Policy))
+	}
+	for _, sink := range o.Sinks {
+		if _, _, err := parseSinkURL(sink); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, o.Redact.validate()...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("log: invalid option: %w", errors.Join(errs...))
+}
+
+// syncLoggers 写入缓冲的日志，忽略标准输出不支持 Sync 的错误
+func syncLoggers(loggers ...*zap.Logger) error {
+	var errs []error
+	for _, logger := range loggers {
+		if err := logger.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTTY) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,193 @@
+package log
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestLoadOption(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.yaml")
+	os.WriteFile(path, []byte("app_name: order\nlevel: info\ndir: /data/logs\nmax_size: 100\ncompress: true\n"), 0o644)
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("LOG_MAX_BACKUPS", "7")
+
+	opt, err := LoadOption(path)
+	if err != nil {
+		t.Fatalf("LoadOption failed: %v", err)
+	}
+	if opt.AppName != "order" || opt.Dir != "/data/logs" || opt.MaxSize != 100 || !opt.Compress {
+		t.Errorf("Unexpected option from file: %+v", opt)
+	}
+	if opt.Level != zap.ErrorLevel || opt.MaxBackups != 7 {
+		t.Errorf("Expected env to override, got level %s, max_backups %d", opt.Level, opt.MaxBackups)
+	}
+
+	jsonPath := filepath.Join(dir, "log.json")
+	os.WriteFile(jsonPath, []byte(`{"app_name":"pay","level":"warn","encoding":"console"}`), 0o644)
+	t.Setenv("LOG_LEVEL", "")
+	os.Unsetenv("LOG_LEVEL")
+	opt, err = LoadOption(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadOption failed: %v", err)
+	}
+	if opt.AppName != "pay" || opt.Level != zap.WarnLevel || opt.Encoding != "console" {
+		t.Errorf("Unexpected option from json: %+v", opt)
+	}
+
+	if _, err := LoadOption(filepath.Join(dir, "log.toml")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	t.Setenv("LOG_DEBUG", "maybe")
+	if _, err := LoadOption(""); err == nil || !strings.Contains(err.Error(), "LOG_DEBUG") {
+		t.Errorf("Expected error for invalid env, got %v", err)
+	}
+}
+
+func TestOptionValidate(t *testing.T) {
+	if err := (Option{}).Validate(); err != nil {
+		t.Errorf("Expected zero option to be valid, got %v", err)
+	}
+	err := Option{Encoding: "xml", MaxSize: -1, Level: 10}.Validate()
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	for _, s := range []string{"invalid level", "invalid encoding", "max_size"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("Expected %q in %v", s, err)
+		}
+	}
+}
+
+// restoreAfterInit 测试结束后恢复 Init 替换的全局日志和日志级别
+func restoreAfterInit(t *testing.T) {
+	oldLogger, oldDataLogger := CurrentLogger(), CurrentDataLogger()
+	oldLevel, oldConfigured := rootLevel.Level(), configuredLevel.Level()
+	t.Cleanup(func() {
+		ReplaceLoggers(oldLogger, oldDataLogger)
+		rootLevel.SetLevel(oldLevel)
+		configuredLevel.SetLevel(oldConfigured)
+	})
+}
+
+func TestInit(t *testing.T) {
+	oldLogger, oldDataLogger := CurrentLogger(), CurrentDataLogger()
+	restoreAfterInit(t)
+
+	// 校验失败时保持原来的日志
+	if _, err := Init(WithEncoding("xml")); err == nil {
+		t.Fatal("Expected error for invalid encoding")
+	}
+	if CurrentLogger() != oldLogger || Logger != oldLogger {
+		t.Fatal("Expected Logger to be unchanged after failed Init")
+	}
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	flush, err := Init(WithDir(dir), WithAppName("order"), WithLevel(zap.InfoLevel))
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if CurrentLogger() == oldLogger || CurrentDataLogger() == oldDataLogger || Logger != CurrentLogger() || DataLogger != CurrentDataLogger() {
+		t.Fatal("Expected globals to be replaced")
+	}
+	if rootLevel.Level() != zap.InfoLevel {
+		t.Errorf("Expected root level info, got %s", rootLevel.Level())
+	}
+
+	Debugf("hidden %d", 1)
+	Infof("visible %d", 2)
+	ILogger().Printf("from iface\n")
+	NewSugoWriter().Printf("select %d", 1)
+	Error("failed")
+	DataLogger.Info("data line")
+	if err := flush(); err != nil {
+		t.Errorf("flush failed: %v", err)
+	}
+
+	info, _ := os.ReadFile(filepath.Join(dir, "order.INFO"))
+	for _, s := range []string{"visible 2", "from iface", "failed"} {
+		if !strings.Contains(string(info), s) {
+			t.Errorf("Expected %q in info log: %s", s, info)
+		}
+	}
+	if strings.Contains(string(info), "hidden") || strings.Contains(string(info), "select") {
+		t.Errorf("Unexpected debug entries in info log: %s", info)
+	}
+	if errorLog, _ := os.ReadFile(filepath.Join(dir, "order.ERROR")); !strings.Contains(string(errorLog), "failed") {
+		t.Errorf("Expected error log, got %s", errorLog)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "order.data")); !strings.Contains(string(data), "data line") {
+		t.Errorf("Expected data log, got %s", data)
+	}
+}
+
+func TestInitKeepsRuntimeLevel(t *testing.T) {
+	restoreAfterInit(t)
+	dir := t.TempDir()
+
+	if _, err := Init(WithDir(dir), WithLevel(zap.InfoLevel)); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if rootLevel.Level() != zap.InfoLevel {
+		t.Fatalf("Expected root level info, got %s", rootLevel.Level())
+	}
+
+	// 配置的级别变化但运行时没有修改过，再次 Init 使用新的配置
+	if _, err := Init(WithDir(dir), WithLevel(zap.WarnLevel)); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if rootLevel.Level() != zap.WarnLevel {
+		t.Fatalf("Expected root level warn, got %s", rootLevel.Level())
+	}
+
+	// 运行时修改的级别在之后的 Init 中保留
+	Level().SetLevel(zap.DebugLevel)
+	for i := 0; i < 2; i++ {
+		if _, err := Init(WithDir(dir), WithLevel(zap.InfoLevel)); err != nil {
+			t.Fatalf("Init failed: %v", err)
+		}
+		if rootLevel.Level() != zap.DebugLevel {
+			t.Errorf("Expected runtime level debug to be kept, got %s", rootLevel.Level())
+		}
+	}
+}
+
+// 其他 goroutine 记录日志时调用 Init，需要通过 go test -race 检查
+func TestInitConcurrent(t *testing.T) {
+	restoreAfterInit(t)
+
+	dir := t.TempDir()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				Infof("concurrent %d", 1)
+				Ctx(context.Background()).Info("concurrent")
+				CurrentDataLogger().Info("data")
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		flush, err := Init(WithDir(dir), WithAppName("order"), WithLevel(zap.InfoLevel))
+		if err != nil {
+			t.Fatalf("Init failed: %v", err)
+		}
+		flush()
+	}
+	close(stop)
+	wg.Wait()
+}
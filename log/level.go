@@ -0,0 +1,209 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// rootLevel 全局 Logger 的日志级别，可通过 Level、LevelHandler 在运行时修改
+var rootLevel = zap.NewAtomicLevelAt(zap.DebugLevel)
+
+// configuredLevel 最近一次 Init 为 rootLevel 设置的级别，rootLevel 与它不同说明运行时修改过
+var configuredLevel = zap.NewAtomicLevelAt(rootLevel.Level())
+
+// Level 返回全局 Logger 的日志级别，修改后立即生效：log.Level().SetLevel(zap.WarnLevel)
+func Level() zap.AtomicLevel {
+	return rootLevel
+}
+
+// namedLevelSet 覆盖级别的不可变快照，修改时整体替换，读取时不加锁
+type namedLevelSet struct {
+	levels map[string]zapcore.Level
+	min    zapcore.Level // 所有覆盖级别中的最低级别
+}
+
+var (
+	namedLevelsMu sync.Mutex // 串行化修改
+	namedLevels   atomic.Pointer[namedLevelSet]
+)
+
+// SetNamedLevel 覆盖指定名字的 logger（Logger.Named(name)）的日志级别，对所有通过 NewLogger 创建的 logger 生效
+// 名字按 . 分级匹配，最长匹配优先：a 的覆盖级别同样作用于 a.b
+func SetNamedLevel(name string, level zapcore.Level) {
+	updateNamedLevels(func(levels map[string]zapcore.Level) {
+		levels[name] = level
+	})
+}
+
+// RemoveNamedLevel 删除指定名字的覆盖级别
+func RemoveNamedLevel(name string) {
+	updateNamedLevels(func(levels map[string]zapcore.Level) {
+		delete(levels, name)
+	})
+}
+
+// NamedLevels 返回所有覆盖级别
+func NamedLevels() map[string]zapcore.Level {
+	result := map[string]zapcore.Level{}
+	if set := namedLevels.Load(); set != nil {
+		for name, level := range set.levels {
+			result[name] = level
+		}
+	}
+	return result
+}
+
+// updateNamedLevels 复制当前的覆盖级别，修改后替换快照
+func updateNamedLevels(update func(levels map[string]zapcore.Level)) {
+	namedLevelsMu.Lock()
+	defer namedLevelsMu.Unlock()
+
+	levels := NamedLevels()
+	update(levels)
+	if len(levels) == 0 {
+		namedLevels.Store(nil)
+		return
+	}
+	set := &namedLevelSet{levels: levels, min: zapcore.InvalidLevel}
+	for _, level := range levels {
+		if set.min == zapcore.InvalidLevel || level < set.min {
+			set.min = level
+		}
+	}
+	namedLevels.Store(set)
+}
+
+// namedLevel 返回名字对应的覆盖级别
+func namedLevel(name string) (zapcore.Level, bool) {
+	set := namedLevels.Load()
+	if set == nil {
+		return 0, false
+	}
+	for name != "" {
+		if level, ok := set.levels[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return 0, false
+}
+
+// leveledCore 按 logger 名字的覆盖级别或默认级别过滤日志，内部的 core 不再按级别过滤
+type leveledCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+// Enabled 没有 logger 名字，只要默认级别或任一覆盖级别允许即返回true，由 Check 精确过滤
+func (c *leveledCore) Enabled(lvl zapcore.Level) bool {
+	if c.level.Enabled(lvl) {
+		return true
+	}
+	set := namedLevels.Load()
+	return set != nil && lvl >= set.min
+}
+
+func (c *leveledCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if level, ok := namedLevel(ent.LoggerName); ok {
+		if ent.Level < level {
+			return ce
+		}
+	} else if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (c *leveledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &leveledCore{Core: c.Core.With(fields), level: c.level}
+}
+
+type levelPayload struct {
+	Name  string `json:"name,omitempty"`
+	Level string `json:"level"`
+}
+
+type levelState struct {
+	Level string            `json:"level"`
+	Named map[string]string `json:"named"`
+}
+
+// LevelHandler 查询、修改日志级别的 http.Handler，可以和 /debug/metrics 挂在一起：
+//
+//	http.Handle("/debug/log/level", log.LevelHandler())
+//
+// GET 返回 {"level":"info","named":{"delaytask":"debug"}}
+// PUT/POST 请求体（或查询参数）为 {"level":"warn"} 修改全局级别，{"name":"delaytask","level":"debug"} 修改覆盖级别，
+// 指定 name 且 level 为空时删除覆盖级别
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var payload levelPayload
+			if r.URL.Query().Has("level") || r.URL.Query().Has("name") {
+				payload.Name = r.URL.Query().Get("name")
+				payload.Level = r.URL.Query().Get("level")
+			} else if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeLevelError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+				return
+			}
+			if err := applyLevel(payload); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		state := levelState{Level: rootLevel.Level().String(), Named: map[string]string{}}
+		names := NamedLevels()
+		keys := make([]string, 0, len(names))
+		for name := range names {
+			keys = append(keys, name)
+		}
+		sort.Strings(keys)
+		for _, name := range keys {
+			state.Named[name] = names[name].String()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+}
+
+func applyLevel(payload levelPayload) error {
+	if payload.Name != "" && payload.Level == "" {
+		RemoveNamedLevel(payload.Name)
+		return nil
+	}
+	level, err := zapcore.ParseLevel(payload.Level)
+	if err != nil {
+		return err
+	}
+	if payload.Name != "" {
+		SetNamedLevel(payload.Name, level)
+	} else {
+		rootLevel.SetLevel(level)
+	}
+	CurrentLogger().Infof("[Log] level changed, name: %q, level: %s", payload.Name, level)
+	return nil
+}
+
+func writeLevelError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,52 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ToggleDebugOnSignal 收到 SIGUSR1 时在 Debug 级别和原级别之间切换全局日志级别，返回停止监听的函数
+// 用于线上临时打开调试日志：kill -USR1 <pid>；stop 可以重复调用
+func ToggleDebugOnSignal() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR1)
+
+	go func() {
+		previous := rootLevel.Level()
+		for {
+			select {
+			case <-ch:
+				current := rootLevel.Level()
+				next := zapcore.DebugLevel
+				if current == zap.DebugLevel {
+					next = previous
+					if next == zap.DebugLevel {
+						next = zap.InfoLevel
+					}
+				} else {
+					previous = current
+				}
+				rootLevel.SetLevel(next)
+				CurrentLogger().Warnf("[Log] level toggled by signal: %s -> %s", current, next)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
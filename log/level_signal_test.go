@@ -0,0 +1,42 @@
+//go:build unix
+
+package log
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestToggleDebugOnSignal(t *testing.T) {
+	old := rootLevel.Level()
+	rootLevel.SetLevel(zap.WarnLevel)
+	stop := ToggleDebugOnSignal()
+	t.Cleanup(func() {
+		stop()
+		rootLevel.SetLevel(old)
+	})
+
+	waitLevel := func(level zapcore.Level) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if rootLevel.Level() == level {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("Expected level %s, got %s", level, rootLevel.Level())
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitLevel(zap.DebugLevel)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitLevel(zap.WarnLevel)
+
+	// 重复调用 stop 不会 panic，Cleanup 中会再调用一次
+	stop()
+}
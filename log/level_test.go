@@ -0,0 +1,121 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLeveledCore(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(&leveledCore{Core: core, level: level})
+	t.Cleanup(func() {
+		RemoveNamedLevel("delaytask")
+		RemoveNamedLevel("api")
+	})
+
+	logger.Debug("dropped")
+	logger.Info("kept")
+	level.SetLevel(zap.WarnLevel)
+	logger.Info("dropped after level change")
+
+	SetNamedLevel("delaytask", zap.DebugLevel)
+	SetNamedLevel("api", zap.ErrorLevel)
+	logger.Named("delaytask").Debug("kept by override")
+	logger.Named("delaytask").Named("lane").Debug("kept by parent override")
+	logger.Named("api").Warn("dropped by override")
+	logger.Named("other").Info("dropped by root level")
+	logger.With(zap.String("k", "v")).Named("delaytask").Debug("kept with fields")
+
+	RemoveNamedLevel("delaytask")
+	logger.Named("delaytask").Debug("dropped after override removed")
+
+	var messages []string
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.Message)
+	}
+	expected := "kept,kept by override,kept by parent override,kept with fields"
+	if got := strings.Join(messages, ","); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestNamedLevelConcurrent(t *testing.T) {
+	core, _ := observer.New(zapcore.DebugLevel)
+	logger := zap.New(&leveledCore{Core: core, level: zap.NewAtomicLevelAt(zap.InfoLevel)}).Named("concurrent.sub")
+	t.Cleanup(func() { RemoveNamedLevel("concurrent") })
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			SetNamedLevel("concurrent", zapcore.Level(i%3-1))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			logger.Debug("debug")
+		}
+	}()
+	wg.Wait()
+
+	SetNamedLevel("concurrent", zap.ErrorLevel)
+	if levels := NamedLevels(); levels["concurrent"] != zap.ErrorLevel {
+		t.Errorf("Unexpected named levels: %v", levels)
+	}
+	if logger.Core().Enabled(zap.DebugLevel) {
+		t.Error("Expected debug to be disabled")
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	old := rootLevel.Level()
+	t.Cleanup(func() {
+		rootLevel.SetLevel(old)
+		RemoveNamedLevel("delaytask")
+	})
+	h := LevelHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/debug/log/level", strings.NewReader(`{"level":"warn"}`)))
+	if w.Code != http.StatusOK || rootLevel.Level() != zap.WarnLevel {
+		t.Fatalf("Expected warn level, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/debug/log/level?name=delaytask&level=debug", nil))
+	expected := `{"level":"warn","named":{"delaytask":"debug"}}` + "\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/debug/log/level", strings.NewReader(`{"name":"delaytask"}`)))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/log/level", nil))
+	expected = `{"level":"warn","named":{}}` + "\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/debug/log/level", strings.NewReader(`{"level":"verbose"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid level, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/debug/log/level", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", w.Code)
+	}
+}
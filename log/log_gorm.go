@@ -1,12 +1,26 @@
 package log
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
 )
 
+// GormLoggerName GormLogger 记录日志使用的 logger 名字，可通过 SetNamedLevel 单独调整SQL日志的级别
+const GormLoggerName = "gorm"
+
+// SugoWriter 实现 gorm/logger.Writer 接口，所有日志按 Debug 级别记录
+//
+// Deprecated: 使用 NewGormLogger，日志带有结构化的SQL、耗时等字段
 type SugoWriter struct {
 	mlog *zap.SugaredLogger
 }
@@ -19,5 +33,163 @@ func (m *SugoWriter) Printf(format string, v ...interface{}) {
 }
 
 func NewSugoWriter() *SugoWriter {
-	return &SugoWriter{mlog: Logger}
+	return &SugoWriter{mlog: CurrentLogger()}
+}
+
+// GormConfig GormLogger 配置
+type GormConfig struct {
+	SlowThreshold             time.Duration       // 慢查询阈值，超过时按 Warn 级别记录，为0时不记录慢查询
+	LogLevel                  gormlogger.LogLevel // 日志级别，Info 时按 Debug 级别记录所有SQL，默认 Warn
+	IgnoreRecordNotFoundError bool                // 不记录 gorm.ErrRecordNotFound 错误
+	ParameterizedQueries      bool                // SQL 中不带参数值，避免记录敏感数据
+}
+
+// DefaultGormConfig 默认配置：慢查询阈值200ms、Warn 级别
+func DefaultGormConfig() GormConfig {
+	return GormConfig{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      gormlogger.Warn,
+	}
+}
+
+// GormLogger 实现 gorm/logger.Interface，按 Ctx 规则带上 context 中的 trace_id 等字段
+// 每次执行SQL记录 sql、rows、elapsed_ms 字段，caller 为业务代码中调用 gorm 的位置：
+//
+//	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: log.NewGormLogger(log.DefaultGormConfig())})
+//	db.WithContext(ctx).First(&user, id)
+type GormLogger struct {
+	config GormConfig
+}
+
+// NewGormLogger 创建 gorm 日志，LogLevel 为0时使用 Warn
+func NewGormLogger(config GormConfig) *GormLogger {
+	if config.LogLevel == 0 {
+		config.LogLevel = gormlogger.Warn
+	}
+	return &GormLogger{config: config}
+}
+
+// LogMode 返回指定级别的副本，gorm 的 Debug() 通过它临时开启 Info 级别
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	c := *l
+	c.config.LogLevel = level
+	return &c
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel < gormlogger.Info {
+		return
+	}
+	if ce := l.check(zapcore.InfoLevel, msg); ce != nil {
+		ce.Message = fmt.Sprintf(msg, data...)
+		l.write(ctx, ce, utils.CallerFrame())
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel < gormlogger.Warn {
+		return
+	}
+	if ce := l.check(zapcore.WarnLevel, msg); ce != nil {
+		ce.Message = fmt.Sprintf(msg, data...)
+		l.write(ctx, ce, utils.CallerFrame())
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.config.LogLevel < gormlogger.Error {
+		return
+	}
+	if ce := l.check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Message = fmt.Sprintf(msg, data...)
+		l.write(ctx, ce, utils.CallerFrame())
+	}
+}
+
+// Trace 记录SQL执行结果：出错时 Error，超过慢查询阈值时 Warn，LogLevel 为 Info 时 Debug
+// 同时通过 SetGormQueryReporter 统计耗时
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	notFound := errors.Is(err, gormlogger.ErrRecordNotFound)
+	failed := err != nil && !notFound
+	slow := l.config.SlowThreshold > 0 && elapsed > l.config.SlowThreshold
+
+	reporter, _ := gormQueryReporter.Load().(func(string, string, time.Duration))
+	logged := l.config.LogLevel >= gormlogger.Info ||
+		(l.config.LogLevel >= gormlogger.Warn && slow) ||
+		(l.config.LogLevel >= gormlogger.Error && err != nil && !(notFound && l.config.IgnoreRecordNotFoundError))
+	if reporter == nil && !logged {
+		return
+	}
+
+	sql, rows := fc()
+	if reporter != nil {
+		result := "success"
+		if failed {
+			result = "fail"
+		}
+		reporter(sqlOperation(sql), result, elapsed)
+	}
+	if !logged {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Float64("elapsed_ms", float64(elapsed.Nanoseconds())/1e6),
+	}
+	level, msg := zapcore.DebugLevel, "sql"
+	switch {
+	case err != nil && l.config.LogLevel >= gormlogger.Error && !(notFound && l.config.IgnoreRecordNotFoundError):
+		level, msg, fields = zapcore.ErrorLevel, "sql error", append(fields, zap.Error(err))
+	case slow && l.config.LogLevel >= gormlogger.Warn:
+		level, msg, fields = zapcore.WarnLevel, "slow sql", append(fields, zap.Duration("threshold", l.config.SlowThreshold))
+	}
+	if ce := l.check(level, msg); ce != nil {
+		l.write(ctx, ce, utils.CallerFrame(), fields...)
+	}
+}
+
+// ParamsFilter 实现 gorm 的 ParamsFilter 接口，ParameterizedQueries 时SQL中不带参数值
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.config.ParameterizedQueries {
+		return sql, nil
+	}
+	return sql, params
+}
+
+// check 按 GormLoggerName 的级别检查日志，未开启时返回nil
+func (l *GormLogger) check(level zapcore.Level, msg string) *zapcore.CheckedEntry {
+	return CurrentLogger().Desugar().Named(GormLoggerName).Check(level, msg)
+}
+
+// write 带上 context 字段写入日志，开启了 caller 时替换为业务代码中调用 gorm 的位置
+// frame 必须在 GormLogger 的导出方法中通过 utils.CallerFrame 获取
+func (l *GormLogger) write(ctx context.Context, ce *zapcore.CheckedEntry, frame runtime.Frame, fields ...zap.Field) {
+	if ce.Caller.Defined {
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, frame.PC != 0)
+	}
+	if ctxFields := ContextFields(ctx); len(ctxFields) > 0 {
+		fields = append(ctxFields, fields...)
+	}
+	ce.Write(fields...)
+}
+
+var gormQueryReporter atomic.Value // func(operation string, result string, elapsed time.Duration)
+
+// SetGormQueryReporter 设置SQL执行后的回调，metric 包初始化时通过它统计SQL耗时
+// operation 为 select、insert、update、delete 或 other，result 为 success 或 fail，gorm.ErrRecordNotFound 视为 success
+func SetGormQueryReporter(fn func(operation string, result string, elapsed time.Duration)) {
+	gormQueryReporter.Store(fn)
+}
+
+// sqlOperation 返回SQL的类型，作为指标的维度
+func sqlOperation(sql string) string {
+	verb, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	switch verb = strings.ToLower(verb); verb {
+	case "select", "insert", "update", "delete":
+		return verb
+	}
+	return "other"
 }
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestGormLoggerTrace(t *testing.T) {
+	logs := observeLogger(t)
+	ctx := context.WithValue(context.Background(), TRACEID, "trace-1")
+	l := NewGormLogger(GormConfig{SlowThreshold: 100 * time.Millisecond, LogLevel: gormlogger.Info})
+
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "SELECT * FROM users WHERE id = 1", 1 }, nil)
+	l.Trace(ctx, time.Now().Add(-200*time.Millisecond), func() (string, int64) { return "UPDATE users SET name = 'a'", 3 }, nil)
+	l.Trace(ctx, time.Now(), func() (string, int64) { return "INSERT INTO users", 0 }, errors.New("duplicate key"))
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	levels := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.ErrorLevel}
+	messages := []string{"sql", "slow sql", "sql error"}
+	for i, entry := range entries {
+		if entry.Level != levels[i] || entry.Message != messages[i] || entry.LoggerName != GormLoggerName {
+			t.Errorf("Unexpected entry %d: %v %s %s", i, entry.Level, entry.Message, entry.LoggerName)
+		}
+		fields := entry.ContextMap()
+		if fields[TRACEID] != "trace-1" {
+			t.Errorf("Expected trace id, got %v", fields)
+		}
+		if !strings.HasSuffix(entry.Caller.File, "log_gorm_test.go") {
+			t.Errorf("Expected caller in test file, got %s", entry.Caller.String())
+		}
+		if _, ok := fields["caller"]; ok {
+			t.Errorf("Expected caller to be encoded by the caller encoder, got field %v", fields["caller"])
+		}
+	}
+	if fields := entries[1].ContextMap(); fields["sql"] != "UPDATE users SET name = 'a'" || fields["rows"] != int64(3) || fields["elapsed_ms"].(float64) < 200 {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+	if entries[2].ContextMap()["error"] != "duplicate key" {
+		t.Errorf("Expected error field, got %v", entries[2].ContextMap())
+	}
+}
+
+func TestGormLoggerLevel(t *testing.T) {
+	logs := observeLogger(t)
+	l := NewGormLogger(GormConfig{SlowThreshold: time.Second, IgnoreRecordNotFoundError: true})
+
+	// Warn 级别不记录正常的SQL，未设置回调时不生成SQL
+	l.Trace(context.Background(), time.Now(), func() (string, int64) {
+		t.Error("Expected fc not to be called")
+		return "", 0
+	}, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, gormlogger.ErrRecordNotFound)
+	l.Info(context.Background(), "ignored %d", 1)
+	if logs.Len() != 0 {
+		t.Errorf("Expected no entries, got %d", logs.Len())
+	}
+
+	l.LogMode(gormlogger.Info).Info(context.Background(), "migrate %s", "users")
+	if logs.Len() != 1 || logs.All()[0].Message != "migrate users" || !strings.HasSuffix(logs.All()[0].Caller.File, "log_gorm_test.go") {
+		t.Errorf("Expected LogMode to enable info, got %v", logs.All())
+	}
+	l.Info(context.Background(), "ignored")
+	if logs.Len() != 1 {
+		t.Error("Expected LogMode to return a copy")
+	}
+
+	if _, params := NewGormLogger(GormConfig{ParameterizedQueries: true}).ParamsFilter(context.Background(), "SELECT ?", 1); params != nil {
+		t.Errorf("Expected params to be dropped, got %v", params)
+	}
+}
+
+func TestGormQueryReporter(t *testing.T) {
+	observeLogger(t)
+	var got []string
+	SetGormQueryReporter(func(operation string, result string, elapsed time.Duration) {
+		got = append(got, operation+" "+result)
+	})
+	t.Cleanup(func() { SetGormQueryReporter(nil) })
+
+	l := NewGormLogger(GormConfig{LogLevel: gormlogger.Silent})
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "  select 1", 1 }, nil)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "DELETE FROM users", 0 }, errors.New("locked"))
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "SELECT 1", 0 }, gormlogger.ErrRecordNotFound)
+	l.Trace(context.Background(), time.Now(), func() (string, int64) { return "BEGIN", 0 }, nil)
+
+	expected := []string{"select success", "delete fail", "select success", "other success"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -3,6 +3,7 @@ package log
 import (
 	"fmt"
 	"os"
+	"sync/atomic"
 	"time"
 
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
@@ -14,13 +15,75 @@ import (
 
 const (
 	TRACEID = "trace_id" // TraceID 在 context 中的 key，用于请求链路追踪
+	SPANID  = "span_id"  // SpanID 在 context 中的 key
+	USERID  = "user_id"  // 用户ID 在 context 中的 key
 )
 
+// Logger、DataLogger 默认输出到标准输出，Init 后替换为按配置创建的日志
+// 为兼容旧代码，Init 仍会给这两个变量赋值，但赋值不是原子的，直接读取与 Init 并发时存在数据竞争
+//
+// Deprecated: 使用 CurrentLogger()、CurrentDataLogger()，它们与 Init 并发调用是安全的
 var (
-	Logger     = NewLogger(WithDebug(true)).Sugar()
+	Logger     = NewLogger(WithDebug(true), WithAtomicLevel(rootLevel)).Sugar()
 	DataLogger = NewDataLogger(WithDebug(true)).Sugar()
 )
 
+// globalLoggers 当前的全局日志，整体原子替换
+type globalLoggers struct {
+	logger     *zap.SugaredLogger
+	dataLogger *zap.SugaredLogger
+	formatter  *zap.Logger // Infof 等格式化函数使用，跳过 logf 和 Infof 两层调用
+	structured *zap.Logger // Ctx 使用，跳过 CtxLogger 的方法一层调用
+	closers    *closerList // Init 创建的日志打开的资源，被替换后关闭
+}
+
+func newGlobalLoggers(logger *zap.SugaredLogger, dataLogger *zap.SugaredLogger) *globalLoggers {
+	return &globalLoggers{
+		logger:     logger,
+		dataLogger: dataLogger,
+		formatter:  logger.Desugar().WithOptions(zap.AddCallerSkip(2)),
+		structured: logger.Desugar().WithOptions(zap.AddCallerSkip(1)),
+	}
+}
+
+var globals atomic.Pointer[globalLoggers]
+
+func init() {
+	globals.Store(newGlobalLoggers(Logger, DataLogger))
+}
+
+// CurrentLogger 返回当前的全局 Logger，包内的 Infof、Ctx 等都通过它记录日志，与 Init 并发调用是安全的
+func CurrentLogger() *zap.SugaredLogger {
+	return globals.Load().logger
+}
+
+// CurrentDataLogger 返回当前的全局 DataLogger，与 Init 并发调用是安全的
+func CurrentDataLogger() *zap.SugaredLogger {
+	return globals.Load().dataLogger
+}
+
+// ReplaceLoggers 替换全局的 Logger、DataLogger，为nil时保持不变，返回恢复原来日志的函数，一般用于测试
+// 直接给 Logger 变量赋值不会影响 CurrentLogger()
+func ReplaceLoggers(logger *zap.SugaredLogger, dataLogger *zap.SugaredLogger) (restore func()) {
+	old := globals.Load()
+	if logger == nil {
+		logger = old.logger
+	}
+	if dataLogger == nil {
+		dataLogger = old.dataLogger
+	}
+	swapLoggers(newGlobalLoggers(logger, dataLogger))
+	return func() { swapLoggers(old) }
+}
+
+// swapLoggers 原子替换全局日志并同步 Logger、DataLogger 变量，返回原来的日志
+func swapLoggers(g *globalLoggers) *globalLoggers {
+	initMu.Lock()
+	defer initMu.Unlock()
+	Logger, DataLogger = g.logger, g.dataLogger
+	return globals.Swap(g)
+}
+
 func NewLogger(opts ...LogOption) *zap.Logger {
 
 	opt := Option{}
@@ -29,6 +92,7 @@ func NewLogger(opts ...LogOption) *zap.Logger {
 	}
 
 	validateOption(&opt)
+	opt.AtomicLevel.SetLevel(opt.Level)
 
 	config := getZapConfig(opt)
 	core := getZapCore(opt)
@@ -85,9 +149,9 @@ func getZapEncoder(opt Option) zapcore.Encoder {
 		config.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 	if opt.Encoding == "json" {
-		return zapcore.NewJSONEncoder(config)
+		return newRedactEncoder(zapcore.NewJSONEncoder(config), opt.Redact)
 	}
-	return zapcore.NewConsoleEncoder(config)
+	return newRedactEncoder(zapcore.NewConsoleEncoder(config), opt.Redact)
 }
 
 // 普通业务日志使用lumberjack来按大小压缩即可
@@ -116,7 +180,8 @@ func getLumberJackWriteSyncer(opt Option, level zapcore.Level) zapcore.WriteSync
 		MaxAge:     opt.MaxAge,     // 文件最大保留天数（约20年）
 		Compress:   opt.Compress,   // 是否压缩旧的日志文件
 	}
-	return zapcore.AddSync(lumberJackLogger)
+	opt.closers.add(lumberJackLogger.Close)
+	return asyncWriteSyncer(zapcore.AddSync(lumberJackLogger), opt, level)
 }
 
 // getFileRotateWriteSyncer lumberjack 按时间压缩有问题，所以使用file-rotatelogs来按时间压缩
@@ -127,9 +192,9 @@ func getFileRotateWriteSyncer(opt Option) zapcore.WriteSyncer {
 	}
 	fileName := opt.Dir + "/" + opt.AppName + ".data"
 
+	// 文件名不带时间格式，不能设置与它同名的软链接，否则文件会被替换为指向自身的链接，写入的日志丢失
 	logs, err := rotatelogs.New(
 		fileName,
-		rotatelogs.WithLinkName(fileName),
 		rotatelogs.WithMaxAge(time.Duration(opt.RotationMaxAge)*time.Hour*24),
 		rotatelogs.WithRotationTime(time.Duration(opt.RotationTime)*time.Hour),
 		rotatelogs.WithRotationSize(opt.RotationSize*1024*1024),
@@ -138,30 +203,39 @@ func getFileRotateWriteSyncer(opt Option) zapcore.WriteSyncer {
 	if err != nil {
 		panic(err)
 	}
-	return zapcore.AddSync(logs)
+	opt.closers.add(logs.Close)
+	return asyncWriteSyncer(zapcore.AddSync(logs), opt, zap.InfoLevel)
 }
 
 // 获取zap core
-// 1. 根据opt.Level 获取当前级别允许的级别
-// 2. 根据级别获取对应日志写入到不同文件
+// 1. 根据级别获取对应日志写入到不同文件
+// 2. 写入 opt.Sinks 中的输出，调用 opt.Hooks 中的钩子
+// 3. 按 opt.Sampling 采样
+// 4. 由 leveledCore 根据 opt.AtomicLevel 和 logger 名字的覆盖级别过滤，支持运行时修改
 func getZapCore(opt Option) zap.Option {
 	warnPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		// 需要同时满足：1) 当前级别允许 2) 是WARN及以上级别
-		return opt.Level.Enabled(lvl) && lvl >= zap.WarnLevel
+		return lvl >= zap.WarnLevel
 	})
 	errorPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return opt.Level.Enabled(lvl) && lvl >= zap.ErrorLevel
+		return lvl >= zap.ErrorLevel
 	})
+	var cores []zapcore.Core
 	if opt.Debug {
-		core := zapcore.NewCore(getZapEncoder(opt), zapcore.AddSync(os.Stdout), zap.DebugLevel)
-		return zap.WrapCore(func(_ zapcore.Core) zapcore.Core { return core })
+		cores = append(cores, zapcore.NewCore(getZapEncoder(opt), zapcore.AddSync(os.Stdout), zap.DebugLevel))
+	} else {
+		infocore := zapcore.NewCore(getZapEncoder(opt), getLumberJackWriteSyncer(opt, zap.InfoLevel), zap.DebugLevel)
+		errorcore := zapcore.NewCore(getZapEncoder(opt), getLumberJackWriteSyncer(opt, zap.ErrorLevel), errorPriority)
+		warncore := zapcore.NewCore(getZapEncoder(opt), getLumberJackWriteSyncer(opt, zap.WarnLevel), warnPriority)
+		cores = append(cores, infocore, errorcore, warncore)
 	}
+	sinks, err := sinkCores(opt)
+	if err != nil {
+		panic(err)
+	}
+	cores = append(cores, sinks...)
+	cores = append(cores, hookCores(opt)...)
 
-	infocore := zapcore.NewCore(getZapEncoder(opt), getLumberJackWriteSyncer(opt, zap.InfoLevel), opt.Level)
-	errorcore := zapcore.NewCore(getZapEncoder(opt), getLumberJackWriteSyncer(opt, zap.ErrorLevel), errorPriority)
-	warncore := zapcore.NewCore(getZapEncoder(opt), getLumberJackWriteSyncer(opt, zap.WarnLevel), warnPriority)
-
-	tee := zapcore.NewTee(infocore, errorcore, warncore)
+	tee := &leveledCore{Core: newSampler(zapcore.NewTee(cores...), opt.Sampling), level: opt.AtomicLevel}
 
 	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
 		return tee
@@ -181,7 +255,7 @@ func getZapDataCore(opt Option) zap.Option {
 	// 设置调用者编码器为空函数（不输出文件行号）
 	encoderConf.EncodeCaller = func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {}
 	// 使用控制台编码器创建编码器
-	encoder := zapcore.NewConsoleEncoder(encoderConf)
+	encoder := newRedactEncoder(zapcore.NewConsoleEncoder(encoderConf), opt.Redact)
 	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
 		return zapcore.NewCore(encoder, getFileRotateWriteSyncer(opt), zapcore.DebugLevel)
 	})
@@ -201,4 +275,7 @@ func validateOption(opt *Option) {
 	if opt.AppName == "" {
 		opt.AppName = "log"
 	}
+	if opt.AtomicLevel == (zap.AtomicLevel{}) {
+		opt.AtomicLevel = zap.NewAtomicLevel()
+	}
 }
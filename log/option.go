@@ -1,32 +1,46 @@
 package log
 
 import (
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// Option 日志配置，可通过 LoadOption 从 YAML/JSON 文件和环境变量加载
 type Option struct {
 	//zap
-	AppName           string        //日志文件前缀
-	Level             zapcore.Level //日志等级
-	Development       bool          //是否是开发模式
-	Encoding          string        // 日志编码, required
-	DisableCaller     bool
-	DisableStacktrace bool
-	CallerSkip        int
+	AppName           string          `yaml:"app_name" json:"app_name"`       //日志文件前缀
+	Level             zapcore.Level   `yaml:"level" json:"level"`             //日志等级
+	AtomicLevel       zap.AtomicLevel `yaml:"-" json:"-"`                     // 运行时可修改的日志等级，创建时设置为 Level，为空时新建
+	Development       bool            `yaml:"development" json:"development"` //是否是开发模式
+	Encoding          string          `yaml:"encoding" json:"encoding"`       // 日志编码, required
+	DisableCaller     bool            `yaml:"disable_caller" json:"disable_caller"`
+	DisableStacktrace bool            `yaml:"disable_stacktrace" json:"disable_stacktrace"`
+	CallerSkip        int             `yaml:"caller_skip" json:"caller_skip"`
 	//lumberjack
-	FileName   string //文件保存地方
-	MaxSize    int    //日志文件小大（M）
-	MaxBackups int    // 最多存在多少个切片文件
-	MaxAge     int    //保存的最大天数
-	Compress   bool
+	FileName   string `yaml:"file_name" json:"file_name"`     //文件保存地方
+	MaxSize    int    `yaml:"max_size" json:"max_size"`       //日志文件小大（M）
+	MaxBackups int    `yaml:"max_backups" json:"max_backups"` // 最多存在多少个切片文件
+	MaxAge     int    `yaml:"max_age" json:"max_age"`         //保存的最大天数
+	Compress   bool   `yaml:"compress" json:"compress"`
 	//file-rotatelogs
-	RotationTime   int   // 日志切割时间间隔（小时）
-	RotationSize   int64 // 日志切割大小（MB）
-	RotationCount  int   // 日志切割数量
-	RotationMaxAge int   // 日志切割最大天数
+	RotationTime   int   `yaml:"rotation_time" json:"rotation_time"`       // 日志切割时间间隔（小时）
+	RotationSize   int64 `yaml:"rotation_size" json:"rotation_size"`       // 日志切割大小（MB）
+	RotationCount  int   `yaml:"rotation_count" json:"rotation_count"`     // 日志切割数量
+	RotationMaxAge int   `yaml:"rotation_max_age" json:"rotation_max_age"` // 日志切割最大天数
+	// 采样
+	Sampling SamplingOption `yaml:"sampling" json:"sampling"`
+	// 异步写入
+	Async AsyncOption `yaml:"async" json:"async"`
+	// 额外的输出和钩子
+	Sinks []string    `yaml:"sinks" json:"sinks"` // 输出地址，如 tcp://127.0.0.1:5170?level=info，见 WithSinks
+	Hooks []LevelHook `yaml:"-" json:"-"`         // 日志钩子，见 WithHook
+	// 脱敏
+	Redact RedactOption `yaml:"redact" json:"redact"`
 	// 其他
-	Dir   string
-	Debug bool
+	Dir   string `yaml:"dir" json:"dir"`
+	Debug bool   `yaml:"debug" json:"debug"`
+
+	closers *closerList // Init 创建日志时记录打开的文件和启动的后台协程，日志被替换后关闭
 }
 
 type LogOption func(opts *Option)
@@ -97,6 +111,13 @@ func WithLevel(level zapcore.Level) LogOption {
 	}
 }
 
+// WithAtomicLevel 使用指定的 AtomicLevel，创建后可通过它在运行时修改日志等级
+func WithAtomicLevel(level zap.AtomicLevel) LogOption {
+	return func(opts *Option) {
+		opts.AtomicLevel = level
+	}
+}
+
 func WithDevelopment(development bool) LogOption {
 	return func(opts *Option) {
 		opts.Development = development
@@ -0,0 +1,482 @@
+package log
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/daozhonglee/go-util/id"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// 脱敏方式
+const (
+	MaskPartial = "partial" // 保留首尾部分字符，如 138****5678
+	MaskHash    = "hash"    // 替换为 sha256:前16位十六进制，相同的值结果相同，便于关联排查
+	MaskFull    = "full"    // 整体替换为 ******
+)
+
+// 内置的敏感信息识别规则
+const (
+	PatternChineseID = "chinese_id" // 18位身份证号码，校验末位校验码
+	PatternBankCard  = "bank_card"  // 3-6开头的16-19位银行卡号，校验 Luhn 校验码
+	PatternPhone     = "phone"      // 11位手机号码
+	PatternEmail     = "email"      // 邮箱地址
+)
+
+// DefaultRedactFields DefaultRedactOption 使用的敏感字段名
+var DefaultRedactFields = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token", "authorization", "cookie",
+	"id_card", "id_number", "phone", "mobile", "email", "bank_card",
+}
+
+// RedactOption 日志脱敏配置，Fields 和 Patterns 都为空时不脱敏
+//   - Fields 中的字段整体脱敏，包括 With、Infow 等结构化字段以及 zap.Any 记录的结构体、map 中的同名字段
+//   - Patterns 对字符串字段的值和日志消息做识别，只替换匹配的部分
+type RedactOption struct {
+	Fields   []string `yaml:"fields" json:"fields"`     // 需要脱敏的字段名，不区分大小写
+	Patterns []string `yaml:"patterns" json:"patterns"` // 需要识别的内置规则，如 phone、chinese_id
+	Style    string   `yaml:"style" json:"style"`       // 脱敏方式，默认 partial
+	HashKey  string   `yaml:"hash_key" json:"hash_key"` // hash 方式的 HMAC 密钥，为空时直接 sha256，手机号等取值范围小的数据可被穷举
+}
+
+// DefaultRedactOption 默认的脱敏配置：DefaultRedactFields 中的字段、所有内置规则、partial 方式
+func DefaultRedactOption() RedactOption {
+	return RedactOption{
+		Fields:   append([]string(nil), DefaultRedactFields...),
+		Patterns: []string{PatternChineseID, PatternBankCard, PatternPhone, PatternEmail},
+		Style:    MaskPartial,
+	}
+}
+
+// WithRedact 开启日志脱敏
+func WithRedact(redact RedactOption) LogOption {
+	return func(opts *Option) {
+		opts.Redact = redact
+	}
+}
+
+// validate 校验脱敏方式和规则名
+func (o RedactOption) validate() []error {
+	var errs []error
+	switch o.Style {
+	case "", MaskPartial, MaskHash, MaskFull:
+	default:
+		errs = append(errs, fmt.Errorf("invalid redact.style %q, must be partial, hash or full", o.Style))
+	}
+	for _, name := range o.Patterns {
+		if findRedactPattern(name) == nil {
+			errs = append(errs, fmt.Errorf("unknown redact.patterns %q", name))
+		}
+	}
+	return errs
+}
+
+type redactPattern struct {
+	name    string
+	re      *regexp.Regexp
+	valid   func(string) bool
+	partial func(string) string
+}
+
+// redactPatterns 按顺序匹配，身份证号在银行卡号之前，已脱敏的部分不会被后面的规则再次匹配
+var redactPatterns = []*redactPattern{
+	{
+		name:    PatternChineseID,
+		re:      regexp.MustCompile(`\b\d{17}[\dXx]\b`),
+		valid:   validChineseID,
+		partial: func(s string) string { return maskMiddle(s, 3, 4) },
+	},
+	{
+		name:    PatternBankCard,
+		re:      regexp.MustCompile(`\b[3-6]\d{15,18}\b`),
+		valid:   validLuhn,
+		partial: func(s string) string { return maskMiddle(s, 4, 4) },
+	},
+	{
+		name:    PatternPhone,
+		re:      regexp.MustCompile(`\b1[3-9]\d{9}\b`),
+		partial: func(s string) string { return maskMiddle(s, 3, 4) },
+	},
+	{
+		name: PatternEmail,
+		re:   regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		partial: func(s string) string {
+			local, domain, _ := strings.Cut(s, "@")
+			return maskMiddle(local, 1, 0) + "@" + domain
+		},
+	},
+}
+
+func findRedactPattern(name string) *redactPattern {
+	for _, p := range redactPatterns {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// validChineseID 格式由 id.ValidateChinese 校验，另外校验末位校验码，避免把18位的订单号等误判为身份证号
+func validChineseID(s string) bool {
+	if !id.ValidateChinese(s) {
+		return false
+	}
+	weights := [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+	return "10X98765432"[sum%11] == strings.ToUpper(s[17:])[0]
+}
+
+func validLuhn(s string) bool {
+	sum := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if (len(s)-i)%2 == 0 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// maskMiddle 保留前 head 个和后 tail 个字符，其余替换为*，字符数不足时全部替换
+func maskMiddle(s string, head int, tail int) string {
+	runes := []rune(s)
+	if len(runes) <= head+tail {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:head]) + strings.Repeat("*", len(runes)-head-tail) + string(runes[len(runes)-tail:])
+}
+
+// redactor 按配置脱敏字段和消息
+type redactor struct {
+	fields   map[string]struct{}
+	patterns []*redactPattern
+	style    string
+	hashKey  []byte
+}
+
+// newRedactor 未配置脱敏时返回nil
+func newRedactor(opt RedactOption) *redactor {
+	if len(opt.Fields) == 0 && len(opt.Patterns) == 0 {
+		return nil
+	}
+	r := &redactor{fields: map[string]struct{}{}, style: opt.Style, hashKey: []byte(opt.HashKey)}
+	for _, name := range opt.Fields {
+		r.fields[strings.ToLower(name)] = struct{}{}
+	}
+	for _, p := range redactPatterns {
+		for _, name := range opt.Patterns {
+			if p.name == name {
+				r.patterns = append(r.patterns, p)
+				break
+			}
+		}
+	}
+	return r
+}
+
+func (r *redactor) sensitive(key string) bool {
+	_, ok := r.fields[strings.ToLower(key)]
+	return ok
+}
+
+// mask 按脱敏方式处理 s，partial 为 partial 方式的处理函数
+func (r *redactor) mask(s string, partial func(string) string) string {
+	switch r.style {
+	case MaskHash:
+		var sum []byte
+		if len(r.hashKey) > 0 {
+			mac := hmac.New(sha256.New, r.hashKey)
+			mac.Write([]byte(s))
+			sum = mac.Sum(nil)
+		} else {
+			h := sha256.Sum256([]byte(s))
+			sum = h[:]
+		}
+		return "sha256:" + hex.EncodeToString(sum)[:16]
+	case MaskFull:
+		return "******"
+	default:
+		return partial(s)
+	}
+}
+
+// maskField 敏感字段整体脱敏，partial 方式保留首尾各1/4、最多4个字符，不足8个字符时全部替换
+func (r *redactor) maskField(s string) string {
+	return r.mask(s, func(s string) string {
+		n := len([]rune(s))
+		if n < 8 {
+			return maskMiddle(s, 0, 0)
+		}
+		return maskMiddle(s, min(n/4, 4), min(n/4, 4))
+	})
+}
+
+// text 替换 s 中匹配内置规则的部分
+func (r *redactor) text(s string) string {
+	if len(r.patterns) == 0 || !strings.ContainsAny(s, "0123456789@") {
+		return s
+	}
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(m string) string {
+			if p.valid != nil && !p.valid(m) {
+				return m
+			}
+			return r.mask(m, p.partial)
+		})
+	}
+	return s
+}
+
+func (r *redactor) value(key string, s string) string {
+	if r.sensitive(key) {
+		return r.maskField(s)
+	}
+	return r.text(s)
+}
+
+// field 返回脱敏后的字段，敏感字段转换为字符串后整体脱敏
+func (r *redactor) field(f zapcore.Field) zapcore.Field {
+	switch f.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return f
+	}
+	if r.sensitive(f.Key) {
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		return zap.String(f.Key, r.maskField(fmt.Sprint(enc.Fields[f.Key])))
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = r.text(f.String)
+	case zapcore.ByteStringType:
+		f.Interface = []byte(r.text(string(f.Interface.([]byte))))
+	case zapcore.ObjectMarshalerType:
+		f.Interface = redactObject{ObjectMarshaler: f.Interface.(zapcore.ObjectMarshaler), r: r}
+	case zapcore.ArrayMarshalerType:
+		f.Interface = redactArray{ArrayMarshaler: f.Interface.(zapcore.ArrayMarshaler), r: r}
+	case zapcore.ReflectType:
+		f.Interface = r.reflected(f.Interface)
+	case zapcore.ErrorType, zapcore.StringerType:
+		// 错误和 Stringer 编码时才转换为字符串，经过脱敏的 AddString 与 With 添加时的结果一致，errorVerbose 等也会脱敏
+		return zap.Inline(redactField{Field: f, r: r})
+	}
+	return f
+}
+
+func (r *redactor) fieldList(fields []zapcore.Field) []zapcore.Field {
+	result := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		result[i] = r.field(f)
+	}
+	return result
+}
+
+// reflected 将 zap.Any 记录的结构体、map 等转换为JSON结构后脱敏，无法转换时原样返回
+func (r *redactor) reflected(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return v
+	}
+	return r.walk("", value)
+}
+
+func (r *redactor) walk(key string, v interface{}) interface{} {
+	if v != nil && r.sensitive(key) {
+		if s, ok := v.(string); ok {
+			return r.maskField(s)
+		}
+		return r.maskField(fmt.Sprint(v))
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, item := range x {
+			x[k] = r.walk(k, item)
+		}
+	case []interface{}:
+		for i, item := range x {
+			x[i] = r.walk("", item)
+		}
+	case string:
+		return r.text(x)
+	}
+	return v
+}
+
+// redactEncoder 脱敏的编码器：EncodeEntry 处理日志消息和字段，Add 系列方法处理 With 添加的字段
+type redactEncoder struct {
+	redactObjectEncoder
+	enc zapcore.Encoder
+}
+
+// newRedactEncoder 按配置包装编码器，未配置脱敏时原样返回
+func newRedactEncoder(enc zapcore.Encoder, opt RedactOption) zapcore.Encoder {
+	r := newRedactor(opt)
+	if r == nil {
+		return enc
+	}
+	return &redactEncoder{redactObjectEncoder: redactObjectEncoder{ObjectEncoder: enc, r: r}, enc: enc}
+}
+
+func (e *redactEncoder) Clone() zapcore.Encoder {
+	enc := e.enc.Clone()
+	return &redactEncoder{redactObjectEncoder: redactObjectEncoder{ObjectEncoder: enc, r: e.r}, enc: enc}
+}
+
+func (e *redactEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	ent.Message = e.r.text(ent.Message)
+	return e.enc.EncodeEntry(ent, e.r.fieldList(fields))
+}
+
+// redactObjectEncoder 脱敏写入的字段，所有 Add 系列方法都经过字段名检查，OpenNamespace 直接使用 ObjectEncoder
+type redactObjectEncoder struct {
+	zapcore.ObjectEncoder
+	r *redactor
+}
+
+func (e *redactObjectEncoder) add(f zapcore.Field) {
+	e.r.field(f).AddTo(e.ObjectEncoder)
+}
+
+func (e *redactObjectEncoder) AddString(key string, value string) { e.add(zap.String(key, value)) }
+
+func (e *redactObjectEncoder) AddByteString(key string, value []byte) {
+	e.add(zap.ByteString(key, value))
+}
+
+func (e *redactObjectEncoder) AddBinary(key string, value []byte) { e.add(zap.Binary(key, value)) }
+
+func (e *redactObjectEncoder) AddBool(key string, value bool) { e.add(zap.Bool(key, value)) }
+
+func (e *redactObjectEncoder) AddComplex128(key string, value complex128) {
+	e.add(zap.Complex128(key, value))
+}
+
+func (e *redactObjectEncoder) AddComplex64(key string, value complex64) {
+	e.add(zap.Complex64(key, value))
+}
+
+func (e *redactObjectEncoder) AddDuration(key string, value time.Duration) {
+	e.add(zap.Duration(key, value))
+}
+
+func (e *redactObjectEncoder) AddFloat64(key string, value float64) { e.add(zap.Float64(key, value)) }
+
+func (e *redactObjectEncoder) AddFloat32(key string, value float32) { e.add(zap.Float32(key, value)) }
+
+func (e *redactObjectEncoder) AddInt(key string, value int) { e.add(zap.Int(key, value)) }
+
+func (e *redactObjectEncoder) AddInt64(key string, value int64) { e.add(zap.Int64(key, value)) }
+
+func (e *redactObjectEncoder) AddInt32(key string, value int32) { e.add(zap.Int32(key, value)) }
+
+func (e *redactObjectEncoder) AddInt16(key string, value int16) { e.add(zap.Int16(key, value)) }
+
+func (e *redactObjectEncoder) AddInt8(key string, value int8) { e.add(zap.Int8(key, value)) }
+
+func (e *redactObjectEncoder) AddTime(key string, value time.Time) { e.add(zap.Time(key, value)) }
+
+func (e *redactObjectEncoder) AddUint(key string, value uint) { e.add(zap.Uint(key, value)) }
+
+func (e *redactObjectEncoder) AddUint64(key string, value uint64) { e.add(zap.Uint64(key, value)) }
+
+func (e *redactObjectEncoder) AddUint32(key string, value uint32) { e.add(zap.Uint32(key, value)) }
+
+func (e *redactObjectEncoder) AddUint16(key string, value uint16) { e.add(zap.Uint16(key, value)) }
+
+func (e *redactObjectEncoder) AddUint8(key string, value uint8) { e.add(zap.Uint8(key, value)) }
+
+func (e *redactObjectEncoder) AddUintptr(key string, value uintptr) { e.add(zap.Uintptr(key, value)) }
+
+func (e *redactObjectEncoder) AddObject(key string, value zapcore.ObjectMarshaler) error {
+	e.add(zap.Object(key, value))
+	return nil
+}
+
+func (e *redactObjectEncoder) AddArray(key string, value zapcore.ArrayMarshaler) error {
+	e.add(zap.Array(key, value))
+	return nil
+}
+
+func (e *redactObjectEncoder) AddReflected(key string, value interface{}) error {
+	e.add(zap.Reflect(key, value))
+	return nil
+}
+
+// redactObject 脱敏 zap.Object 记录的对象
+type redactObject struct {
+	zapcore.ObjectMarshaler
+	r *redactor
+}
+
+func (o redactObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.ObjectMarshaler.MarshalLogObject(&redactObjectEncoder{ObjectEncoder: enc, r: o.r})
+}
+
+// redactField 通过脱敏的编码器编码字段，用于编码时才转换为字符串的 zap.Error、zap.Stringer
+type redactField struct {
+	zapcore.Field
+	r *redactor
+}
+
+func (f redactField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	f.Field.AddTo(&redactObjectEncoder{ObjectEncoder: enc, r: f.r})
+	return nil
+}
+
+// redactArray 脱敏 zap.Array 记录的数组，数组元素没有字段名，只按内置规则识别
+type redactArray struct {
+	zapcore.ArrayMarshaler
+	r *redactor
+}
+
+func (a redactArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.ArrayMarshaler.MarshalLogArray(&redactArrayEncoder{ArrayEncoder: enc, r: a.r})
+}
+
+type redactArrayEncoder struct {
+	zapcore.ArrayEncoder
+	r *redactor
+}
+
+func (e *redactArrayEncoder) AppendString(value string) { e.ArrayEncoder.AppendString(e.r.text(value)) }
+
+func (e *redactArrayEncoder) AppendByteString(value []byte) {
+	e.ArrayEncoder.AppendByteString([]byte(e.r.text(string(value))))
+}
+
+func (e *redactArrayEncoder) AppendObject(value zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactObject{ObjectMarshaler: value, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendArray(value zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactArray{ArrayMarshaler: value, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendReflected(value interface{}) error {
+	return e.ArrayEncoder.AppendReflected(e.r.reflected(value))
+}
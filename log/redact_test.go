@@ -0,0 +1,166 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactLogger 返回使用脱敏编码器的 JSON 日志，输出写入 buf
+func redactLogger(opt RedactOption) (*zap.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	config := zap.NewProductionEncoderConfig()
+	config.TimeKey = ""
+	enc := newRedactEncoder(zapcore.NewJSONEncoder(config), opt)
+	return zap.New(zapcore.NewCore(enc, zapcore.AddSync(buf), zapcore.DebugLevel)), buf
+}
+
+type payload struct {
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Password string `json:"password"`
+	Remark   string `json:"remark"`
+}
+
+func TestRedactFields(t *testing.T) {
+	logger, buf := redactLogger(DefaultRedactOption())
+
+	logger.With(zap.String("token", "abcdefghijklmnop")).Info("login",
+		zap.Int64("mobile", 13812345678),
+		zap.String("order_id", "202401010000000001"),
+		zap.Any("req", payload{Name: "alice", Phone: "13812345678", Password: "p@ss", Remark: "联系 alice@example.com"}),
+	)
+
+	out := buf.String()
+	for _, expected := range []string{
+		`"token":"abcd********mnop"`,
+		`"mobile":"13*******78"`,
+		`"order_id":"202401010000000001"`,
+		`"phone":"13*******78"`,
+		`"password":"****"`,
+		`"remark":"联系 a****@example.com"`,
+		`"name":"alice"`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %s in %s", expected, out)
+		}
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	logger, buf := redactLogger(RedactOption{Patterns: []string{PatternChineseID, PatternBankCard, PatternPhone, PatternEmail}})
+
+	logger.Sugar().Infof("user 11010519491231002X card 4111111111111111 phone 13812345678 mail bob@example.com")
+	logger.Info("batch", zap.Strings("phones", []string{"13812345678", "none"}), zap.String("id", "110105194912310021"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := `user 110***********002X card 4111********1111 phone 138****5678 mail b**@example.com`
+	if !strings.Contains(lines[0], expected) {
+		t.Errorf("Expected message %q, got %s", expected, lines[0])
+	}
+	// 校验码错误的18位数字不是身份证号
+	if !strings.Contains(lines[1], `"phones":["138****5678","none"]`) || !strings.Contains(lines[1], `"id":"110105194912310021"`) {
+		t.Errorf("Unexpected fields: %s", lines[1])
+	}
+}
+
+type userID string
+
+func (u userID) String() string { return "user " + string(u) }
+
+func TestRedactErrorStringer(t *testing.T) {
+	logger, buf := redactLogger(DefaultRedactOption())
+	err := errors.New("user 13812345678 not found")
+
+	// 调用时传入的字段与 With 添加的字段结果一致
+	logger.Error("call site", zap.Error(err), zap.Stringer("user", userID("13812345678")))
+	logger.With(zap.Error(err), zap.Stringer("user", userID("13812345678"))).Error("with")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %s", buf.String())
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "13812345678") {
+			t.Errorf("Expected phone number to be masked: %s", line)
+		}
+		for _, expected := range []string{`"error":"user 138****5678 not found"`, `"user":"user 138****5678"`} {
+			if !strings.Contains(line, expected) {
+				t.Errorf("Expected %s in %s", expected, line)
+			}
+		}
+	}
+}
+
+func TestRedactObjectNonString(t *testing.T) {
+	logger, buf := redactLogger(DefaultRedactOption())
+
+	logger.Info("object", zap.Object("user", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddUint32("name", 7)
+		enc.AddUint64("mobile", 13812345678)
+		enc.AddFloat64("phone", 13812345678)
+		enc.AddInt32("bank_card", 62220211)
+		enc.AddBool("secret", true)
+		enc.AddBinary("token", []byte("abcdefghijklmnop"))
+		enc.AddDuration("cookie", 123456789*time.Millisecond)
+		return nil
+	})))
+
+	out := buf.String()
+	for _, leaked := range []string{"13812345678", "1.3812345678e+10", "62220211", "true", "YWJjZGVmZ2hpamtsbW5vcA", "34h17m36.789s"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("Expected %s to be masked: %s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, `"name":7`) || !strings.Contains(out, `"secret":"****"`) {
+		t.Errorf("Unexpected output: %s", out)
+	}
+}
+
+func TestRedactStyle(t *testing.T) {
+	logger, buf := redactLogger(RedactOption{Fields: []string{"phone"}, Style: MaskHash})
+	logger.Info("a", zap.String("phone", "13812345678"))
+	logger.Info("b", zap.String("phone", "13812345678"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.Contains(lines[0], `"phone":"sha256:`) || lines[0][strings.Index(lines[0], "sha256"):] != lines[1][strings.Index(lines[1], "sha256"):] {
+		t.Errorf("Expected stable hash, got %v", lines)
+	}
+
+	keyed, buf2 := redactLogger(RedactOption{Fields: []string{"phone"}, Style: MaskHash, HashKey: "secret"})
+	keyed.Info("a", zap.String("phone", "13812345678"))
+	if strings.Contains(buf2.String(), lines[0][strings.Index(lines[0], "sha256"):]) {
+		t.Error("Expected hash key to change the result")
+	}
+
+	full, buf3 := redactLogger(RedactOption{Fields: []string{"phone"}, Style: MaskFull})
+	full.Info("a", zap.String("phone", "13812345678"))
+	if !strings.Contains(buf3.String(), `"phone":"******"`) {
+		t.Errorf("Unexpected output: %s", buf3.String())
+	}
+}
+
+func TestRedactDisabled(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	if newRedactEncoder(enc, RedactOption{}) != enc {
+		t.Error("Expected encoder to be returned as is")
+	}
+}
+
+func TestRedactValidate(t *testing.T) {
+	err := Option{Redact: RedactOption{Style: "mask", Patterns: []string{"phone", "ssn"}}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "redact.style") || !strings.Contains(err.Error(), `"ssn"`) {
+		t.Errorf("Expected redact errors, got %v", err)
+	}
+
+	t.Setenv("LOG_REDACT_FIELDS", "password, token")
+	opt, err := LoadOption("")
+	if err != nil || len(opt.Redact.Fields) != 2 || opt.Redact.Fields[1] != "token" {
+		t.Errorf("Unexpected fields %v: %v", opt.Redact.Fields, err)
+	}
+}
@@ -0,0 +1,149 @@
+package log
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 日志被丢弃的原因
+const (
+	DropReasonSampling  = "sampling"   // 被采样丢弃
+	DropReasonRateLimit = "rate_limit" // 被 Limit 限流丢弃
+	DropReasonOverflow  = "overflow"   // 异步写入的缓冲区满被丢弃，level 为对应日志文件的级别
+	DropReasonStopped   = "stopped"    // 异步写入停止后（日志被 Init 替换）仍在写入被丢弃
+)
+
+// SamplingOption 日志采样配置，每个周期内相同级别、相同消息的日志先记录 Initial 条，之后每 Thereafter 条记录一条
+// 按消息分组：包内的 Infof、Errorx、Limit 等格式化函数按格式化模板分组，参数不同的日志归为一组；
+// 结构化日志（Ctx、Infow 等）为固定的消息；直接调用 CurrentLogger().Infof 时按格式化后的消息分组
+type SamplingOption struct {
+	Interval   int `yaml:"interval" json:"interval"`     // 采样周期（秒），默认1秒
+	Initial    int `yaml:"initial" json:"initial"`       // 每个周期先记录的条数，为0时不采样
+	Thereafter int `yaml:"thereafter" json:"thereafter"` // 之后每多少条记录一条，为0时丢弃之后的所有日志
+}
+
+// WithSampling 开启日志采样
+func WithSampling(initial int, thereafter int, interval int) LogOption {
+	return func(opts *Option) {
+		opts.Sampling = SamplingOption{Interval: interval, Initial: initial, Thereafter: thereafter}
+	}
+}
+
+var dropReporter atomic.Value // func(level zapcore.Level, reason string)
+
+// SetDropReporter 设置日志被丢弃时的回调，metric 包初始化时通过它统计丢弃的日志数量
+func SetDropReporter(fn func(level zapcore.Level, reason string)) {
+	dropReporter.Store(fn)
+}
+
+func reportDrop(level zapcore.Level, reason string) {
+	if fn, ok := dropReporter.Load().(func(zapcore.Level, string)); ok && fn != nil {
+		fn(level, reason)
+	}
+}
+
+// newSampler 按配置包装采样，未开启时原样返回
+func newSampler(core zapcore.Core, opt SamplingOption) zapcore.Core {
+	if opt.Initial <= 0 {
+		return core
+	}
+	interval := time.Duration(opt.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	thereafter := opt.Thereafter
+	if thereafter <= 0 {
+		thereafter = int(^uint(0) >> 1) // 周期内不再记录
+	}
+	return zapcore.NewSamplerWithOptions(core, interval, opt.Initial, thereafter,
+		zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped != 0 {
+				reportDrop(ent.Level, DropReasonSampling)
+			}
+		}))
+}
+
+// LimitedLogger 按调用位置限流的日志，见 Limit
+type LimitedLogger struct {
+	n   int
+	per time.Duration
+}
+
+// Limit 返回按调用位置限流的日志，同一调用位置每 per 时间内最多记录 n 条，超过的日志被丢弃并通过 SetDropReporter 统计：
+//
+//	log.Limit(10, time.Minute).Errorf("query order %d failed: %v", id, err)
+func Limit(n int, per time.Duration) LimitedLogger {
+	return LimitedLogger{n: n, per: per}
+}
+
+type limitWindow struct {
+	mu    sync.Mutex
+	start time.Time
+	count int
+}
+
+var limitWindows sync.Map // callSite -> *limitWindow
+
+type callSite struct {
+	pc  uintptr
+	n   int
+	per time.Duration
+}
+
+// allow 按调用 LimitedLogger 方法的位置计数
+func (l LimitedLogger) allow(level zapcore.Level) bool {
+	if !CurrentLogger().Desugar().Core().Enabled(level) {
+		return false
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	key := callSite{pc: pcs[0], n: l.n, per: l.per}
+
+	v, ok := limitWindows.Load(key)
+	if !ok {
+		v, _ = limitWindows.LoadOrStore(key, &limitWindow{})
+	}
+	w := v.(*limitWindow)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	if now.Sub(w.start) >= l.per {
+		w.start = now
+		w.count = 0
+	}
+	if w.count >= l.n {
+		reportDrop(level, DropReasonRateLimit)
+		return false
+	}
+	w.count++
+	return true
+}
+
+func (l LimitedLogger) Debugf(format string, a ...interface{}) {
+	if l.allow(zapcore.DebugLevel) {
+		logf(zapcore.DebugLevel, format, a)
+	}
+}
+
+func (l LimitedLogger) Infof(format string, a ...interface{}) {
+	if l.allow(zapcore.InfoLevel) {
+		logf(zapcore.InfoLevel, format, a)
+	}
+}
+
+func (l LimitedLogger) Warnf(format string, a ...interface{}) {
+	if l.allow(zapcore.WarnLevel) {
+		logf(zapcore.WarnLevel, format, a)
+	}
+}
+
+func (l LimitedLogger) Errorf(format string, a ...interface{}) {
+	if l.allow(zapcore.ErrorLevel) {
+		logf(zapcore.ErrorLevel, format, a)
+	}
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordDrops 记录被丢弃的日志，测试结束后恢复
+func recordDrops(t *testing.T) func() map[string]int {
+	var mu sync.Mutex
+	drops := map[string]int{}
+	SetDropReporter(func(level zapcore.Level, reason string) {
+		mu.Lock()
+		defer mu.Unlock()
+		drops[level.String()+" "+reason]++
+	})
+	t.Cleanup(func() { SetDropReporter(nil) })
+	return func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		return drops
+	}
+}
+
+func TestSampling(t *testing.T) {
+	drops := recordDrops(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(newSampler(core, SamplingOption{Interval: 60, Initial: 2, Thereafter: 3}))
+
+	for i := 0; i < 8; i++ {
+		logger.Error("db timeout")
+	}
+	logger.Warn("db timeout")
+	logger.Error("other")
+
+	// 第1、2、5、8条 db timeout 被记录，Warn 和其他消息单独计数
+	if got := logs.FilterMessage("db timeout").FilterLevelExact(zapcore.ErrorLevel).Len(); got != 4 {
+		t.Errorf("Expected 4 sampled entries, got %d", got)
+	}
+	if logs.Len() != 6 {
+		t.Errorf("Expected 6 entries, got %d", logs.Len())
+	}
+	if got := drops()["error sampling"]; got != 4 {
+		t.Errorf("Expected 4 dropped entries, got %v", drops())
+	}
+
+	if newSampler(core, SamplingOption{}) != core {
+		t.Error("Expected sampling to be disabled without Initial")
+	}
+}
+
+func TestSamplingFormatTemplate(t *testing.T) {
+	drops := recordDrops(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	sampled := zap.New(newSampler(core, SamplingOption{Interval: 60, Initial: 2}), zap.AddCaller()).Sugar()
+	t.Cleanup(ReplaceLoggers(sampled, nil))
+
+	// 参数不同的日志按模板归为一组
+	for i := 0; i < 5; i++ {
+		Errorf("query order %d failed", i)
+	}
+	Errorx(context.Background(), "query user %d failed", 1)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 || entries[0].Message != "query order 0 failed" || entries[1].Message != "query order 1 failed" {
+		t.Fatalf("Unexpected entries: %v", entries)
+	}
+	if got := drops()["error sampling"]; got != 3 {
+		t.Errorf("Expected 3 dropped entries, got %v", drops())
+	}
+	Limit(1, time.Minute).Warnf("limited %d", 1)
+	for _, entry := range logs.AllUntimed() {
+		if !strings.HasSuffix(entry.Caller.File, "sampling_test.go") {
+			t.Errorf("Expected caller in test file, got %s", entry.Caller.File)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	logs := observeLogger(t)
+	drops := recordDrops(t)
+	limitWindows.Clear()
+	t.Cleanup(limitWindows.Clear)
+
+	for i := 0; i < 5; i++ {
+		Limit(2, time.Minute).Errorf("query order %d failed", i)
+	}
+	// 不同调用位置单独计数
+	Limit(2, time.Minute).Errorf("another call site")
+
+	if logs.Len() != 3 {
+		t.Errorf("Expected 3 entries, got %d: %v", logs.Len(), logs.All())
+	}
+	if got := drops()["error rate_limit"]; got != 3 {
+		t.Errorf("Expected 3 dropped entries, got %v", drops())
+	}
+
+	// 时间窗口结束后重新计数
+	for i := 0; i < 3; i++ {
+		Limit(1, time.Millisecond).Warnf("window")
+		time.Sleep(2 * time.Millisecond)
+	}
+	if got := logs.FilterMessage("window").Len(); got != 3 {
+		t.Errorf("Expected 3 entries after window reset, got %d", got)
+	}
+}
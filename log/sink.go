@@ -0,0 +1,196 @@
+package log
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 网络输出默认的连接、写入超时
+const defaultSinkTimeout = 3 * time.Second
+
+// 网络输出断开后重连的等待时间，每次失败翻倍
+const (
+	minSinkBackoff = time.Second
+	maxSinkBackoff = time.Minute
+)
+
+// sinkFactories 包内提供的 zap 输出，第一次使用 Option.Sinks 时注册
+var (
+	sinkFactories = map[string]func(*url.URL) (zap.Sink, error){
+		"tcp": newNetSink,
+		"udp": newNetSink,
+	}
+	registerSinksOnce sync.Once
+)
+
+// registerSinks 注册 sinkFactories，scheme 已被其他包注册时使用已注册的输出，不覆盖也不报错
+func registerSinks() {
+	registerSinksOnce.Do(func() {
+		for scheme, factory := range sinkFactories {
+			zap.RegisterSink(scheme, factory)
+		}
+	})
+}
+
+// WithSinks 添加额外的日志输出，URL 格式见 Option.Sinks
+func WithSinks(sinks ...string) LogOption {
+	return func(opts *Option) {
+		opts.Sinks = append(opts.Sinks, sinks...)
+	}
+}
+
+// sinkCores 为 opt.Sinks 中的每个输出创建JSON格式的 core，内置 scheme：
+//   - file:///var/log/app.json，stdout、stderr，由 zap 提供
+//   - tcp://127.0.0.1:5170、udp://127.0.0.1:5171，每条日志一行JSON，UDP 每条日志一个数据包，参数 timeout 为连接、写入超时，默认3秒
+//     TCP 未配置 Option.Async 时使用默认的异步写入；连接失败后等待1秒重连，之后每次失败等待时间翻倍，最长1分钟，等待期间的写入直接返回错误
+//   - syslog://127.0.0.1:514?tag=app&facility=local0，host 为空时写入本机 syslog，参数 network 默认 udp
+//
+// 所有输出都支持参数 level 设置最低级别，如 tcp://127.0.0.1:5170?level=warn，其他 scheme 可通过 zap.RegisterSink 注册
+// 内置 scheme 在第一次使用时注册，已被其他包注册时使用其他包的输出
+func sinkCores(opt Option) ([]zapcore.Core, error) {
+	if len(opt.Sinks) == 0 {
+		return nil, nil
+	}
+	encOpt := opt
+	encOpt.Debug = false
+	encOpt.Encoding = "json"
+
+	registerSinks()
+	cores := make([]zapcore.Core, 0, len(opt.Sinks))
+	for _, raw := range opt.Sinks {
+		path, level, err := parseSinkURL(raw)
+		if err != nil {
+			return nil, err
+		}
+		ws, closeSink, err := zap.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("log: open sink %s: %w", raw, err)
+		}
+		opt.closers.add(func() error {
+			closeSink()
+			return nil
+		})
+
+		// UDP、syslog 每次写入为一条消息，不能合并写入；TCP 始终异步写入，连接异常时不阻塞记录日志
+		switch u, _ := url.Parse(path); u.Scheme {
+		case "udp", "syslog":
+		case "tcp":
+			asyncOpt := opt
+			if asyncOpt.Async.Size <= 0 {
+				asyncOpt.Async = AsyncOption{Size: defaultAsyncSize, Policy: OverflowDrop}
+			}
+			ws = asyncWriteSyncer(ws, asyncOpt, level)
+		default:
+			ws = asyncWriteSyncer(ws, opt, level)
+		}
+		cores = append(cores, zapcore.NewCore(getZapEncoder(encOpt), ws, level))
+	}
+	return cores, nil
+}
+
+// parseSinkURL 解析并去掉 level 参数，zap 的 file 输出不允许带参数
+func parseSinkURL(raw string) (string, zapcore.Level, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", zapcore.DebugLevel, fmt.Errorf("log: invalid sink %q: %w", raw, err)
+	}
+	query := u.Query()
+	if !query.Has("level") {
+		return raw, zapcore.DebugLevel, nil
+	}
+
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(query.Get("level"))); err != nil {
+		return "", zapcore.DebugLevel, fmt.Errorf("log: invalid sink %q: %w", raw, err)
+	}
+	query.Del("level")
+	u.RawQuery = query.Encode()
+	return u.String(), level, nil
+}
+
+// netSink TCP/UDP 输出，写入失败时关闭连接，等待 backoff 后的下一次写入时重新连接
+type netSink struct {
+	network string
+	addr    string
+	timeout time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	backoff time.Duration // 连续失败后的等待时间，写入成功后清零
+	retryAt time.Time     // 在此之前的写入直接返回错误，不再连接
+}
+
+func newNetSink(u *url.URL) (zap.Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing address in %s", u)
+	}
+	timeout := defaultSinkTimeout
+	if v := u.Query().Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout in %s: %w", u, err)
+		}
+		timeout = d
+	}
+	return &netSink{network: u.Scheme, addr: u.Host, timeout: timeout}, nil
+}
+
+func (s *netSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if now := time.Now(); now.Before(s.retryAt) {
+			return 0, fmt.Errorf("log: sink %s://%s unavailable, retry in %s", s.network, s.addr, s.retryAt.Sub(now).Round(time.Millisecond))
+		}
+		conn, err := net.DialTimeout(s.network, s.addr, s.timeout)
+		if err != nil {
+			s.fail()
+			return 0, err
+		}
+		s.conn = conn
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	n, err := s.conn.Write(p)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.fail()
+		return n, err
+	}
+	s.backoff = 0
+	return n, nil
+}
+
+// fail 连接或写入失败后延长重连的等待时间
+func (s *netSink) fail() {
+	s.backoff *= 2
+	if s.backoff < minSinkBackoff {
+		s.backoff = minSinkBackoff
+	}
+	if s.backoff > maxSinkBackoff {
+		s.backoff = maxSinkBackoff
+	}
+	s.retryAt = time.Now().Add(s.backoff)
+}
+
+func (s *netSink) Sync() error {
+	return nil
+}
+
+func (s *netSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
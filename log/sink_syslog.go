@@ -0,0 +1,57 @@
+//go:build unix
+
+package log
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern": syslog.LOG_KERN, "user": syslog.LOG_USER, "mail": syslog.LOG_MAIL, "daemon": syslog.LOG_DAEMON,
+	"auth": syslog.LOG_AUTH, "syslog": syslog.LOG_SYSLOG, "local0": syslog.LOG_LOCAL0, "local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2, "local3": syslog.LOG_LOCAL3, "local4": syslog.LOG_LOCAL4, "local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6, "local7": syslog.LOG_LOCAL7,
+}
+
+func init() {
+	sinkFactories["syslog"] = newSyslogSink
+}
+
+// syslogSink syslog 输出，每条日志按 info 级别发送，日志级别在JSON的 level 字段中
+type syslogSink struct {
+	*syslog.Writer
+}
+
+func newSyslogSink(u *url.URL) (zap.Sink, error) {
+	query := u.Query()
+	facility := syslog.LOG_LOCAL0
+	if name := query.Get("facility"); name != "" {
+		f, ok := syslogFacilities[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown syslog facility %q", name)
+		}
+		facility = f
+	}
+
+	network := ""
+	if u.Host != "" {
+		network = query.Get("network")
+		if network == "" {
+			network = "udp"
+		}
+	}
+	w, err := syslog.Dial(network, u.Host, facility|syslog.LOG_INFO, query.Get("tag"))
+	if err != nil {
+		return nil, err
+	}
+	return syslogSink{Writer: w}, nil
+}
+
+func (s syslogSink) Sync() error {
+	return nil
+}
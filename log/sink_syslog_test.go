@@ -0,0 +1,37 @@
+//go:build unix
+
+package log
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	logger := NewLogger(WithDebug(true), WithSinks("syslog://"+pc.LocalAddr().String()+"?tag=order&facility=local1"))
+	logger.Error("syslog error")
+
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Expected syslog message: %v", err)
+	}
+	// local1(17)*8 + info(6) = 142
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<142>") || !strings.Contains(msg, "order[") || !strings.Contains(msg, `"msg":"syslog error"`) {
+		t.Errorf("Unexpected syslog message: %s", msg)
+	}
+
+	if _, err := sinkCores(Option{Sinks: []string{"syslog://127.0.0.1:514?facility=nope"}}); err == nil {
+		t.Error("Expected unknown facility to fail")
+	}
+}
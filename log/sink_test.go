@@ -0,0 +1,189 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestTCPSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	logger := NewLogger(WithDir(t.TempDir()), WithSinks("tcp://"+ln.Addr().String()+"?level=warn"))
+	logger.Info("tcp info")
+	logger.Warn("tcp warn")
+
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"msg":"tcp warn"`) || !strings.HasPrefix(line, "{") {
+			t.Errorf("Expected warn entry as JSON, got %s", line)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected entry on tcp sink")
+	}
+}
+
+func TestUDPSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	logger := NewLogger(WithDebug(true), WithSinks("udp://"+pc.LocalAddr().String()))
+	logger.Error("udp error")
+
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Expected datagram: %v", err)
+	}
+	if !strings.Contains(string(buf[:n]), `"msg":"udp error"`) {
+		t.Errorf("Unexpected datagram: %s", buf[:n])
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	logger := NewLogger(WithDebug(true), WithSinks("file://"+path+"?level=error"))
+	logger.Warn("file warn")
+	logger.Error("file error")
+	logger.Sync()
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "file error") || strings.Contains(string(data), "file warn") {
+		t.Errorf("Unexpected file content: %s", data)
+	}
+}
+
+func TestNetSinkReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	sink := &netSink{network: "tcp", addr: addr, timeout: time.Second}
+	if _, err := sink.Write([]byte("x\n")); err == nil {
+		t.Error("Expected error without listener")
+	}
+	if sink.conn != nil {
+		t.Error("Expected no connection after failure")
+	}
+
+	// 等待重连期间直接返回错误，不再连接
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("Listen %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	if _, err := sink.Write([]byte("x\n")); err == nil || !strings.Contains(err.Error(), "retry in") || sink.conn != nil {
+		t.Errorf("Expected write to fail fast during backoff, got %v", err)
+	}
+	if sink.backoff != minSinkBackoff {
+		t.Errorf("Expected backoff %s, got %s", minSinkBackoff, sink.backoff)
+	}
+	sink.fail()
+	if sink.backoff != 2*minSinkBackoff {
+		t.Errorf("Expected backoff to double, got %s", sink.backoff)
+	}
+
+	sink.retryAt = time.Time{}
+	if _, err := sink.Write([]byte("x\n")); err != nil || sink.backoff != 0 {
+		t.Errorf("Expected reconnect after backoff, got %v, backoff %s", err, sink.backoff)
+	}
+	sink.Close()
+}
+
+func TestInitClosesReplacedSinks(t *testing.T) {
+	restoreAfterInit(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	closed := make(chan struct{})
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+
+	dir := t.TempDir()
+	flush, err := Init(WithDir(dir), WithAppName("app"), WithSinks("tcp://"+ln.Addr().String()))
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	Info("connect")
+	flush()
+	if _, err := Init(WithDir(dir), WithAppName("app")); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected connection of the replaced logger to be closed")
+	}
+}
+
+func TestRegisterSinksExisting(t *testing.T) {
+	scheme := fmt.Sprintf("logtest%d", time.Now().UnixNano()) // zap 的注册无法撤销，-count 多次运行时使用不同的 scheme
+	factory := func(*url.URL) (zap.Sink, error) { return nil, errors.New("first") }
+	if err := zap.RegisterSink(scheme, factory); err != nil {
+		t.Fatal(err)
+	}
+	sinkFactories[scheme] = newNetSink
+	registerSinksOnce = sync.Once{}
+	t.Cleanup(func() { delete(sinkFactories, scheme) })
+
+	// 已被注册的 scheme 不会 panic，使用先注册的输出
+	registerSinks()
+	if _, _, err := zap.Open(scheme + "://127.0.0.1:1"); err == nil || !strings.Contains(err.Error(), "first") {
+		t.Errorf("Expected the existing sink factory, got %v", err)
+	}
+}
+
+func TestSinkValidate(t *testing.T) {
+	err := Option{Sinks: []string{"tcp://127.0.0.1:5170?level=loud"}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "loud") {
+		t.Errorf("Expected invalid level error, got %v", err)
+	}
+	if _, err := sinkCores(Option{Sinks: []string{"unknown://x"}}); err == nil {
+		t.Error("Expected unknown scheme to fail")
+	}
+}
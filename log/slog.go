@@ -0,0 +1,170 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler 写入 zap core 的 slog.Handler，使用 log/slog 的第三方库的日志与业务日志写入相同的文件
+// 日志带有 context 中的字段（见 ContextFields），slog 的 group 输出为嵌套的对象
+type SlogHandler struct {
+	logger *zap.Logger // 为nil时使用当前的 Logger，Init 之后仍然生效
+	fields []zapcore.Field
+	groups []string // WithGroup 打开但还没有字段的 group，没有字段的 group 不输出
+}
+
+// NewSlogHandler 创建写入 logger 的 slog.Handler，logger 为nil时使用当前的 Logger
+func NewSlogHandler(logger *zap.Logger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+// Slog 返回写入 Logger 的 slog.Logger，可通过 slog.SetDefault(log.Slog()) 设置为默认的 slog
+func Slog() *slog.Logger {
+	return slog.New(NewSlogHandler(nil))
+}
+
+func (h *SlogHandler) zapLogger() *zap.Logger {
+	if h.logger != nil {
+		return h.logger
+	}
+	return CurrentLogger().Desugar()
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.zapLogger().Core().Enabled(zapLevel(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	logger := h.zapLogger()
+	ent := zapcore.Entry{
+		LoggerName: logger.Name(),
+		Time:       r.Time,
+		Level:      zapLevel(r.Level),
+		Message:    r.Message,
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	ce := logger.Core().Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	// context 中的字段不属于任何 group，放在最前面
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	ce.Write(append(ContextFields(ctx), h.withAttrs(attrs)...)...)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := h.withAttrs(attrs)
+	if len(fields) == len(h.fields) {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, fields: fields}
+}
+
+// withAttrs 返回添加 attrs 之后的字段，有字段时先打开 groups 中的 group
+func (h *SlogHandler) withAttrs(attrs []slog.Attr) []zapcore.Field {
+	fields := appendSlogAttrs(nil, attrs)
+	if len(fields) == 0 {
+		return h.fields
+	}
+	result := make([]zapcore.Field, 0, len(h.fields)+len(h.groups)+len(fields))
+	result = append(result, h.fields...)
+	for _, name := range h.groups {
+		result = append(result, zap.Namespace(name))
+	}
+	return append(result, fields...)
+}
+
+// WithGroup 之后的字段都在 name 对象中
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, fields: h.fields, groups: append(h.groups[:len(h.groups):len(h.groups)], name)}
+}
+
+// zapLevel slog 的级别转换为不高于它的 zap 级别，如 slog.LevelWarn+2 转换为 Warn
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func appendSlogAttr(fields []zapcore.Field, a slog.Attr) []zapcore.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		// key 为空的 group 展开到当前层级
+		if a.Key == "" {
+			return appendSlogAttrs(fields, attrs)
+		}
+		return append(fields, zap.Object(a.Key, slogGroup(attrs)))
+	}
+	return append(fields, slogField(a))
+}
+
+func slogField(a slog.Attr) zapcore.Field {
+	v := a.Value
+	switch v.Kind() {
+	case slog.KindString:
+		return zap.String(a.Key, v.String())
+	case slog.KindInt64:
+		return zap.Int64(a.Key, v.Int64())
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, v.Uint64())
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, v.Float64())
+	case slog.KindBool:
+		return zap.Bool(a.Key, v.Bool())
+	case slog.KindDuration:
+		return zap.Duration(a.Key, v.Duration())
+	case slog.KindTime:
+		return zap.Time(a.Key, v.Time())
+	}
+	if err, ok := v.Any().(error); ok {
+		return zap.NamedError(a.Key, err)
+	}
+	return zap.Any(a.Key, v.Any())
+}
+
+// slogGroup 将 slog 的 group 输出为对象
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range appendSlogAttrs(nil, g) {
+		f.AddTo(enc)
+	}
+	return nil
+}
+
+func appendSlogAttrs(fields []zapcore.Field, attrs []slog.Attr) []zapcore.Field {
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, a)
+	}
+	return fields
+}
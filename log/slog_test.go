@@ -0,0 +1,74 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSlogHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	config := zap.NewProductionEncoderConfig()
+	config.TimeKey = slog.TimeKey
+	config.LevelKey = slog.LevelKey
+	config.MessageKey = slog.MessageKey
+	config.CallerKey = ""
+	logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(config), zapcore.AddSync(&buf), zapcore.DebugLevel))
+
+	slogtest.Run(t, func(t *testing.T) slog.Handler {
+		buf.Reset()
+		return NewSlogHandler(logger)
+	}, func(t *testing.T) map[string]any {
+		var m map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+			t.Fatalf("invalid json %q: %v", buf.String(), err)
+		}
+		return m
+	})
+}
+
+func TestSlog(t *testing.T) {
+	logs := observeLogger(t)
+	ctx := context.WithValue(context.Background(), TRACEID, "trace-1")
+
+	logger := Slog().With("module", "order").WithGroup("req")
+	logger.DebugContext(ctx, "debug")
+	logger.Log(ctx, slog.LevelWarn+2, "warn", "id", 1, slog.Group("user", "name", "alice"))
+	logger.ErrorContext(ctx, "failed", "err", errors.New("boom"))
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	levels := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.ErrorLevel}
+	for i, entry := range entries {
+		if entry.Level != levels[i] {
+			t.Errorf("Expected level %v, got %v", levels[i], entry.Level)
+		}
+		if !strings.HasSuffix(entry.Caller.File, "slog_test.go") {
+			t.Errorf("Expected caller in test file, got %s", entry.Caller.File)
+		}
+		if fields := entry.ContextMap(); fields[TRACEID] != "trace-1" || fields["module"] != "order" {
+			t.Errorf("Expected context and handler fields, got %v", fields)
+		}
+	}
+	// 没有字段的 group 不输出
+	if _, ok := entries[0].ContextMap()["req"]; ok {
+		t.Errorf("Expected empty group to be omitted, got %v", entries[0].ContextMap())
+	}
+	req, _ := entries[1].ContextMap()["req"].(map[string]interface{})
+	if req["id"] != int64(1) || req["user"].(map[string]interface{})["name"] != "alice" {
+		t.Errorf("Unexpected group fields: %v", entries[1].ContextMap())
+	}
+	if req, _ := entries[2].ContextMap()["req"].(map[string]interface{}); req["err"] != "boom" {
+		t.Errorf("Expected error field, got %v", entries[2].ContextMap())
+	}
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// NewTraceID 生成链路ID，默认为32位十六进制字符串（与 W3C Trace Context 的 trace-id 一致），可在启动时替换
+var NewTraceID = func() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ParseTraceID 返回上游传入的链路ID，依次尝试 traceID、W3C traceparent、requestID，都不合法时返回空字符串
+// HTTP 与 gRPC 分别从请求头和 metadata 中取值，保证两者的规则一致
+func ParseTraceID(traceID string, traceparent string, requestID string) string {
+	if traceID := validTraceID(traceID); traceID != "" {
+		return traceID
+	}
+	// traceparent: version-traceid-parentid-flags
+	if parts := strings.Split(traceparent, "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		if traceID := validTraceID(parts[1]); traceID != "" && traceID != strings.Repeat("0", 32) {
+			return traceID
+		}
+	}
+	return validTraceID(requestID)
+}
+
+// validTraceID 链路ID会写入日志和响应头，只接受长度不超过128的字母、数字、-、_
+func validTraceID(traceID string) string {
+	if len(traceID) == 0 || len(traceID) > 128 {
+		return ""
+	}
+	for _, c := range traceID {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '_') {
+			return ""
+		}
+	}
+	return traceID
+}
+
+// TraceID 返回 context 中的链路ID，没有时返回空字符串
+func TraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(TRACEID).(string)
+	return traceID
+}
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceID(t *testing.T) {
+	tests := []struct {
+		name                            string
+		traceID, traceparent, requestID string
+		want                            string
+	}{
+		{"trace id", "abc", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "req-1", "abc"},
+		{"traceparent", "", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "req-1", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"invalid traceparent", "", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", ""},
+		{"request id", "a\nb", "", "req-1", "req-1"},
+		{"empty", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTraceID(tt.traceID, tt.traceparent, tt.requestID); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if id := NewTraceID(); len(id) != 32 || ParseTraceID(id, "", "") != id {
+		t.Errorf("Unexpected generated trace id %q", id)
+	}
+	if TraceID(context.WithValue(context.Background(), TRACEID, "t-1")) != "t-1" || TraceID(context.Background()) != "" {
+		t.Error("Unexpected trace id from context")
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/daozhonglee/go-util/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap/zapcore"
 )
 
 var cv *CounterVec
@@ -15,6 +18,8 @@ var gv *GaugeVec
 var grpcTimer *Timer
 var httpTimer *Timer
 var customTimer *Timer
+var logDroppedCounter *CounterVec
+var gormTimer *Timer
 
 type Server struct {
 	lsnAddr string
@@ -27,19 +32,33 @@ func (s *Server) Start() {
 		return
 	}
 
-	http.Handle("/debug/metrics", promhttp.Handler())
+	// 采集、输出指标的错误写入日志
+	http.Handle("/debug/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{ErrorLog: log.NewPromLogger()})))
 	err = http.Serve(listener, nil)
 	if err != nil {
 		log.CRITICAL("[MetricServer] Serve err: %v, lsn: %v", err, s.lsnAddr)
 		return
 	}
-	log.INFO("metric server start success!")
+	log.Infof("metric server start success!")
 }
 
 func initBaseMetric(module string) {
 	cv = NewCounterVec(NameSpaceSugo, fmt.Sprintf("%s_%s", module, "counter"), "counter_vec", []string{"target", "detail", "result"})
 	gv = NewGaugeVec(NameSpaceSugo, fmt.Sprintf("%s_%s", module, "gauge"), "gauge_vec", []string{"target", "detail", "result"})
 	customTimer = NewTimer(NameSpaceSugo, fmt.Sprintf("%s_%s", module, "timer"), "custom_timer", []string{"action", "result"})
+
+	// 统计被采样、限流丢弃的日志数量
+	logDroppedCounter = NewCounterVec(NameSpaceSugo, fmt.Sprintf("%s_%s", module, "log_dropped"), "log_dropped", []string{"level", "reason"})
+	log.SetDropReporter(func(level zapcore.Level, reason string) {
+		logDroppedCounter.Inc(level.String(), reason)
+	})
+
+	// 统计 log.GormLogger 记录的SQL耗时
+	gormTimer = NewTimer(NameSpaceSugo, fmt.Sprintf("%s_%s", module, "gorm"), "gorm_timer", []string{"operation", "result"})
+	log.SetGormQueryReporter(func(operation string, result string, elapsed time.Duration) {
+		gormTimer.Observe(elapsed, operation, result)
+	})
 }
 
 // Init InitGRPC，初始化GRPC
@@ -49,7 +68,7 @@ func Init(listen string, module string) {
 
 	initBaseMetric(module)
 	grpcTimer = NewTimer(NameSpaceSugo, fmt.Sprintf("%s_%s", module, "grpc"), "grpc_timer", []string{"action", "result"})
-	log.DEBUG("[Metric] Init listen: %s, module: %s", listen, module)
+	log.Debugf("[Metric] Init listen: %s, module: %s", listen, module)
 }
 
 // InitHttp 初始化Http
@@ -59,7 +78,7 @@ func InitHttp(listen string, module string) {
 
 	initBaseMetric(module)
 	httpTimer = NewTimer(NameSpaceSugo, fmt.Sprintf("%s_%s", module, "http"), "http_timer", []string{"method", "uri", "code"})
-	log.DEBUG("[Metric] InitHttp listen: %s, module: %s", listen, module)
+	log.Debugf("[Metric] InitHttp listen: %s, module: %s", listen, module)
 }
 
 func InitJob(listen string, module string) {
@@ -67,7 +86,7 @@ func InitJob(listen string, module string) {
 	go server.Start()
 
 	initBaseMetric(module)
-	log.DEBUG("[Metric] InitJob listen: %s, module: %s", listen, module)
+	log.Debugf("[Metric] InitJob listen: %s, module: %s", listen, module)
 }
 
 func IncCounter(labels ...string) {
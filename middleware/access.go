@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/daozhonglee/go-util/log"
+)
+
+// AccessLogSkipper 返回true的请求不记录访问日志，如健康检查
+var AccessLogSkipper = func(r *http.Request) bool {
+	return false
+}
+
+// AccessLog 通过 log.CurrentLogger() 记录访问日志，5xx 使用 Error 级别，4xx 使用 Warn 级别
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if AccessLogSkipper(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		rw := wrap(w)
+		next.ServeHTTP(rw, r)
+
+		status := rw.Status()
+		fields := []interface{}{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"query", r.URL.RawQuery,
+			"status", status,
+			"bytes", rw.bytes,
+			"latency", time.Since(start),
+			"remote", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		}
+		if traceID := TraceID(r.Context()); traceID != "" {
+			fields = append(fields, log.TRACEID, traceID)
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.CurrentLogger().Errorw("[HTTP] access", fields...)
+		case status >= http.StatusBadRequest:
+			log.CurrentLogger().Warnw("[HTTP] access", fields...)
+		default:
+			log.CurrentLogger().Infow("[HTTP] access", fields...)
+		}
+	})
+}
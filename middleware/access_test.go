@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAccessLog(t *testing.T) {
+	logs := observeLogger(t)
+	h := Chain(Trace, AccessLog)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	}))
+
+	r := httptest.NewRequest("GET", "/users/1?x=1", nil)
+	r.Header.Set("X-Trace-Id", "trace-1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Level != zapcore.WarnLevel {
+		t.Errorf("Expected warn level for 404, got %v", entries[0].Level)
+	}
+	fields := entries[0].ContextMap()
+	if fields["status"] != int64(404) || fields["bytes"] != int64(9) || fields["path"] != "/users/1" || fields["trace_id"] != "trace-1" {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+}
+
+func TestAccessLogSkipper(t *testing.T) {
+	logs := observeLogger(t)
+	AccessLogSkipper = func(r *http.Request) bool { return r.URL.Path == "/health" }
+	defer func() { AccessLogSkipper = func(r *http.Request) bool { return false } }()
+
+	h := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+	if logs.Len() != 0 {
+		t.Errorf("Expected no entry, got %d", logs.Len())
+	}
+}
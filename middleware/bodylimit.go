@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/daozhonglee/go-util/api"
+)
+
+// BodyLimit 限制请求体大小，Content-Length 超过 limit 时直接返回413
+// 未声明长度的请求体读取超过 limit 时返回错误，api.Bind 将其转换为 api.ErrBodyTooLarge
+func BodyLimit(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				api.WriteError(w, r, api.ErrBodyTooLarge)
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/daozhonglee/go-util/api"
+)
+
+func TestBodyLimit(t *testing.T) {
+	type request struct {
+		Name string `json:"name"`
+	}
+	h := BodyLimit(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := api.Bind(r, &req); err != nil {
+			api.WriteError(w, r, err)
+			return
+		}
+		api.WriteData(w, r, req.Name)
+	}))
+
+	// Content-Length 超过限制
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"a very long name"}`)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+
+	// 未声明长度，读取时超过限制
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"a very long name"}`))
+	r.ContentLength = -1
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 from Bind, got %d: %s", w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"bob"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
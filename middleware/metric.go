@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/daozhonglee/go-util/metric"
+)
+
+// Metric 通过 metric.BeginTimerHttp 记录请求耗时，需要先调用 metric.InitHttp
+// uri 标签使用 http.ServeMux 匹配到的路由（如 GET /users/{id}），避免路径参数导致标签过多，未匹配时为 unmatched
+func Metric(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tf := metric.BeginTimerHttp()
+		if tf == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rw := wrap(w)
+		next.ServeHTTP(rw, r)
+		metric.EndTimerHttp(tf, r.Method, routeOf(r), rw.Status())
+	})
+}
+
+// routeOf ServeMux 会在请求上记录匹配到的路由
+func routeOf(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return "unmatched"
+}
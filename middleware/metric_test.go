@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daozhonglee/go-util/metric"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetric(t *testing.T) {
+	// 未初始化时直接调用下一个 handler
+	called := false
+	Metric(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !called {
+		t.Fatal("Expected handler to be called")
+	}
+
+	metric.InitHttp("0", "middleware_test")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	Metric(mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != metric.NameSpaceSugo+"_middleware_test_http_h" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["uri"] == "GET /users/{id}" && labels["code"] == "204" && m.GetHistogram().GetSampleCount() == 1 {
+				return
+			}
+		}
+		t.Fatalf("Unexpected metrics: %v", family.GetMetric())
+	}
+	t.Fatal("Expected http timer metric to be registered")
+}
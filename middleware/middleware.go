@@ -0,0 +1,90 @@
+// Package middleware 提供 net/http 通用中间件：链路ID、访问日志、耗时监控、panic恢复、请求体大小限制
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Middleware net/http 中间件
+type Middleware func(http.Handler) http.Handler
+
+// Chain 组合中间件，第一个中间件在最外层
+func Chain(mws ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// Default 常用中间件组合：Trace、AccessLog、Metric、Recover
+// Recover 在最内层，panic 转换的错误响应会被访问日志和监控记录
+func Default(next http.Handler) http.Handler {
+	return Chain(Trace, AccessLog, Metric, Recover)(next)
+}
+
+// responseWriter 记录HTTP状态码和响应体大小，通过 Unwrap 支持 http.ResponseController
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// wrap 多个中间件共用同一个 responseWriter
+func wrap(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Status 返回已写入的HTTP状态码，未写入时返回200
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// written 是否已经写入响应头
+func (w *responseWriter) written() bool {
+	return w.status != 0
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("middleware: Hijack not supported")
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daozhonglee/go-util/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLogger 将 log.Logger 替换为可观察的 logger，测试结束后恢复
+func observeLogger(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	t.Cleanup(log.ReplaceLoggers(zap.New(core).Sugar(), nil))
+	return logs
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := Chain(mark("a"), mark("b"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if len(order) != 3 || order[0] != "a" || order[1] != "b" || order[2] != "handler" {
+		t.Errorf("Unexpected order: %v", order)
+	}
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	w := httptest.NewRecorder()
+	rw := wrap(w)
+	if wrap(rw) != rw {
+		t.Error("Expected wrap to reuse responseWriter")
+	}
+	if err := http.NewResponseController(rw).Flush(); err != nil {
+		t.Errorf("Flush failed: %v", err)
+	}
+	if !w.Flushed || rw.Status() != http.StatusOK {
+		t.Errorf("Expected flushed with status 200, got %v %d", w.Flushed, rw.Status())
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/daozhonglee/go-util/api"
+	"github.com/daozhonglee/go-util/errorutil"
+	"github.com/daozhonglee/go-util/log"
+)
+
+// Recover 恢复 handler 中的panic，记录带调用栈的错误日志并返回 api.Error 格式的500响应
+// 响应已经开始写入时只记录日志；http.ErrAbortHandler 继续panic，由 net/http 中断连接
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := wrap(w)
+		err := errorutil.Try(func() error {
+			next.ServeHTTP(rw, r)
+			return nil
+		})
+		if err == nil {
+			return
+		}
+		if errors.Is(err, http.ErrAbortHandler) {
+			panic(http.ErrAbortHandler)
+		}
+
+		log.ErrorStackx(r.Context(), err, "[HTTP] panic %s %s", r.Method, r.URL.Path)
+		if !rw.written() {
+			api.WriteError(rw, r, err)
+		}
+	})
+}
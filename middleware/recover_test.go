@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daozhonglee/go-util/api"
+)
+
+func TestRecover(t *testing.T) {
+	logs := observeLogger(t)
+	h := Chain(Trace, Recover)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["x"] = 1
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	expected := `{"message":"` + api.DefaultErrorMessage + `","code":-1}`
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+	if logs.Len() != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", logs.Len())
+	}
+	if _, ok := logs.All()[0].ContextMap()["error"]; !ok {
+		t.Errorf("Expected error field, got %v", logs.All()[0].ContextMap())
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	observeLogger(t)
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+		t.Errorf("Expected original response to be kept, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("Expected ErrAbortHandler to be re-panicked, got %v", r)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/daozhonglee/go-util/api"
+	"github.com/daozhonglee/go-util/log"
+)
+
+// Trace 从请求头获取链路ID，没有时生成新的链路ID，写入 context 的 log.TRACEID 和 api.TraceIDHeader 响应头
+// 依次尝试 api.TraceIDHeader、W3C traceparent、api.RequestIDHeader 请求头
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID := TraceIDFromHeader(r.Header)
+		if traceID == "" {
+			traceID = log.NewTraceID()
+		}
+		w.Header().Set(api.TraceIDHeader, traceID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), log.TRACEID, traceID)))
+	})
+}
+
+// TraceIDFromHeader 从请求头获取链路ID，没有时返回空字符串，规则见 log.ParseTraceID
+func TraceIDFromHeader(header http.Header) string {
+	return log.ParseTraceID(header.Get(api.TraceIDHeader), header.Get("traceparent"), header.Get(api.RequestIDHeader))
+}
+
+// TraceID 返回 context 中的链路ID，没有时返回空字符串
+func TraceID(ctx context.Context) string {
+	return log.TraceID(ctx)
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daozhonglee/go-util/api"
+)
+
+func TestTrace(t *testing.T) {
+	var got string
+	h := Trace(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = TraceID(r.Context())
+	}))
+
+	// 生成新的链路ID
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if len(got) != 32 || w.Header().Get(api.TraceIDHeader) != got {
+		t.Errorf("Expected generated trace id, got %q, header %q", got, w.Header().Get(api.TraceIDHeader))
+	}
+
+	// 沿用请求头中的链路ID
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(api.TraceIDHeader, "upstream-1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if got != "upstream-1" {
+		t.Errorf("Expected upstream-1, got %q", got)
+	}
+}
+
+func TestTraceIDFromHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   string
+	}{
+		{"trace header", map[string]string{"X-Trace-Id": "abc"}, "abc"},
+		{"traceparent", map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"invalid traceparent", map[string]string{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, ""},
+		{"request id", map[string]string{"X-Request-Id": "req-1"}, "req-1"},
+		{"unsafe value", map[string]string{"X-Trace-Id": "a\nb"}, ""},
+		{"empty", map[string]string{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			if got := TraceIDFromHeader(header); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}